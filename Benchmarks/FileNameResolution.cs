@@ -0,0 +1,53 @@
+using BenchmarkDotNet.Attributes;
+using System;
+using System.TBA;
+
+namespace Benchmarks;
+
+// Every launch of a bare file name stats the candidate directories (executable directory, current directory, PATH entries).
+// Resolving it once and marking the path as pre-resolved skips all of these syscalls.
+// The wall time is dominated by the process creation, so the number of resolutions per launch is printed as well.
+public class FileNameResolution
+{
+    private static readonly string FileName = OperatingSystem.IsWindows() ? "cmd" : "true";
+    private static readonly string[] ChildArguments = OperatingSystem.IsWindows() ? ["/c", "exit"] : [];
+
+    private string _resolvedPath = null!;
+    private long _initialResolutionCount;
+    private long _launchCount;
+
+    [GlobalSetup]
+    public void Setup()
+    {
+        _resolvedPath = ProcessStartOptions.ResolvePath(FileName).FileName;
+        _initialResolutionCount = ProcessStartOptions.FileNameResolutionCount;
+    }
+
+    [GlobalCleanup]
+    public void Cleanup()
+        => Console.WriteLine($"// File name resolutions per launch: {(double)(ProcessStartOptions.FileNameResolutionCount - _initialResolutionCount) / _launchCount:0.###}");
+
+    [Benchmark(Baseline = true)]
+    public int ResolveOnEveryLaunch()
+    {
+        _launchCount++;
+        ProcessStartOptions options = new(FileName) { Arguments = [.. ChildArguments] };
+        return ChildProcess.Discard(options).ExitCode;
+    }
+
+    [Benchmark]
+    public int ResolutionCache()
+    {
+        _launchCount++;
+        ProcessStartOptions options = new(FileName) { Arguments = [.. ChildArguments], FileNameResolutionCacheTimeToLive = TimeSpan.FromMinutes(1) };
+        return ChildProcess.Discard(options).ExitCode;
+    }
+
+    [Benchmark]
+    public int PreResolvedExecutable()
+    {
+        _launchCount++;
+        ProcessStartOptions options = new(_resolvedPath) { Arguments = [.. ChildArguments], PreResolvedExecutable = true };
+        return ChildProcess.Discard(options).ExitCode;
+    }
+}
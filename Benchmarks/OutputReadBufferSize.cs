@@ -0,0 +1,37 @@
+using BenchmarkDotNet.Attributes;
+using System;
+using System.TBA;
+using System.Threading.Tasks;
+
+namespace Benchmarks;
+
+public class OutputReadBufferSize
+{
+    // null stands for the default: every read fills as much of the capture buffer as is available.
+    [Params(null, 1024, 64 * 1024, 1024 * 1024)]
+    public int? ReadBufferSize { get; set; }
+
+    private ProcessStartOptions CreateOptions()
+    {
+        // A chatty child process that writes a few MB to standard output.
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "for /L %i in (1,1,50000) do @echo Line number %i of the chatty child process output" } }
+            : new("sh") { Arguments = { "-c", "seq 1 500000" } };
+        options.OutputReadBufferSize = ReadBufferSize;
+        return options;
+    }
+
+    [Benchmark]
+    public int CaptureOutput()
+    {
+        ProcessOutput processOutput = ChildProcess.CaptureOutput(CreateOptions());
+        return processOutput.ExitStatus.ExitCode ^ processOutput.StandardOutput.Length;
+    }
+
+    [Benchmark]
+    public async Task<int> CaptureOutputAsync()
+    {
+        ProcessOutput processOutput = await ChildProcess.CaptureOutputAsync(CreateOptions());
+        return processOutput.ExitStatus.ExitCode ^ processOutput.StandardOutput.Length;
+    }
+}
@@ -0,0 +1,47 @@
+using BenchmarkDotNet.Attributes;
+using System;
+using System.TBA;
+using System.Threading.Tasks;
+
+namespace Benchmarks;
+
+public class OutputSpanConsumer
+{
+    // The allocated memory of the span consumer benchmarks should not grow with the size of the output.
+    [Params(10_000, 500_000)]
+    public int LineCount { get; set; }
+
+    private int _newLines;
+
+    private ProcessStartOptions CreateOptions()
+        => OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", $"for /L %i in (1,1,{LineCount}) do @echo %i" } }
+            : new("seq") { Arguments = { "1", $"{LineCount}" } };
+
+    [Benchmark(Baseline = true)]
+    public int CaptureOutput()
+    {
+        ProcessOutput processOutput = ChildProcess.CaptureOutput(CreateOptions());
+        return processOutput.StandardOutput.AsSpan().Count('\n');
+    }
+
+    [Benchmark]
+    public int SpanConsumer()
+    {
+        _newLines = 0;
+
+        ChildProcess.StreamOutput(CreateOptions(), CountNewLines);
+        return _newLines;
+    }
+
+    [Benchmark]
+    public async Task<int> SpanConsumerAsync()
+    {
+        _newLines = 0;
+
+        await ChildProcess.StreamOutputAsync(CreateOptions(), CountNewLines);
+        return _newLines;
+    }
+
+    private void CountNewLines(ReadOnlySpan<byte> chunk) => _newLines += chunk.Count((byte)'\n');
+}
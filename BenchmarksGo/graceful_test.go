@@ -0,0 +1,92 @@
+//go:build unix
+
+package main
+
+import (
+	"bufio"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// gracePeriod is how long we wait after sending SIGTERM before escalating to SIGKILL.
+const gracePeriod = 500 * time.Millisecond
+
+// Both children report "ready" once the SIGTERM disposition is set, so we never signal them too early.
+// They busy-loop rather than run sleep, as sh runs traps only between commands.
+const (
+	trapTermScript   = `trap 'exit 0' TERM; echo ready; while :; do :; done`
+	ignoreTermScript = `trap '' TERM; echo ready; while :; do :; done`
+)
+
+// BenchmarkGraceful_TrapsSigTerm measures how long it takes to stop a child that handles SIGTERM and exits.
+// It should be far below the grace period, as no escalation is needed.
+func BenchmarkGraceful_TrapsSigTerm(b *testing.B) {
+	benchmarkGracefulShutdown(b, trapTermScript, false)
+}
+
+// BenchmarkGraceful_IgnoresSigTerm measures how long it takes to stop a child that ignores SIGTERM.
+// It's expected to be slightly above the grace period, as the child needs to be killed with SIGKILL.
+func BenchmarkGraceful_IgnoresSigTerm(b *testing.B) {
+	benchmarkGracefulShutdown(b, ignoreTermScript, true)
+}
+
+func benchmarkGracefulShutdown(b *testing.B, script string, expectKill bool) {
+	var total time.Duration
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cmd := exec.Command("sh", "-c", script)
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			b.Fatalf("Failed to create stdout pipe: %v", err)
+		}
+		if err := cmd.Start(); err != nil {
+			b.Fatalf("Failed to start command: %v", err)
+		}
+		if _, err := bufio.NewReader(stdout).ReadString('\n'); err != nil {
+			b.Fatalf("Failed to wait for the child to get ready: %v", err)
+		}
+		b.StartTimer()
+
+		start := time.Now()
+		killed, err := stopGracefully(cmd, gracePeriod)
+		total += time.Since(start)
+		if err != nil {
+			b.Fatalf("Failed to stop command: %v", err)
+		}
+		if killed != expectKill {
+			b.Fatalf("Expected killed=%v, got %v", expectKill, killed)
+		}
+	}
+
+	b.ReportMetric(total.Seconds()*1000/float64(b.N), "stop-ms/op")
+}
+
+// stopGracefully sends SIGTERM and waits up to gracePeriod for the process to exit, then escalates to SIGKILL.
+// It returns true when the escalation was needed.
+func stopGracefully(cmd *exec.Cmd, gracePeriod time.Duration) (bool, error) {
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		return false, err
+	}
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case err := <-exited:
+		return false, err
+	case <-timer.C:
+		if err := cmd.Process.Kill(); err != nil {
+			return true, err
+		}
+		// Wait reports "signal: killed", which is exactly what we expect here.
+		<-exited
+		return true, nil
+	}
+}
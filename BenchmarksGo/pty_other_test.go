@@ -0,0 +1,10 @@
+//go:build unix && !linux
+
+package main
+
+import "os"
+
+// openPty is implemented only for Linux, the benchmarks using it are skipped elsewhere.
+func openPty() (master *os.File, slave *os.File, err error) {
+	return nil, nil, errPtyUnsupported
+}
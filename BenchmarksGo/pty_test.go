@@ -0,0 +1,107 @@
+//go:build unix
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// blockBufferedProgram prints the first line and keeps running for a while without flushing stdout.
+// awk uses C stdio, so its stdout is block-buffered when it's a pipe and line-buffered when it's a terminal.
+// We use a busy loop rather than system("sleep") or a command pipe, as awk flushes its output before running other commands.
+var blockBufferedProgram = []string{"awk", `BEGIN { print "first"; for (i = 0; i < 10000000; i++) {} print "second" }`}
+
+// errPtyUnsupported is returned by openPty on systems where allocating a PTY is not implemented.
+var errPtyUnsupported = errors.New("allocating a PTY is not supported on this OS")
+
+// BenchmarkPtyVsPipe_TimeToFirstLine measures how long it takes to receive the first line of a block-buffered program
+// when its stdout is a plain pipe versus a PTY. With a pipe, the first line arrives only when the program exits
+// (or fills its stdio buffer), with a PTY it arrives as soon as it's printed. This is the interactivity benefit
+// that PTY-based line buffering targets. The difference is reported as the diff-ms/op metric.
+func BenchmarkPtyVsPipe_TimeToFirstLine(b *testing.B) {
+	if _, err := exec.LookPath(blockBufferedProgram[0]); err != nil {
+		b.Skipf("%s is not available: %v", blockBufferedProgram[0], err)
+	}
+	if master, slave, err := openPty(); err != nil {
+		b.Skipf("Failed to allocate a PTY: %v", err)
+	} else {
+		master.Close()
+		slave.Close()
+	}
+
+	var pipeTotal, ptyTotal time.Duration
+	for i := 0; i < b.N; i++ {
+		pipeTotal += timeToFirstLineOverPipe(b)
+		ptyTotal += timeToFirstLineOverPty(b)
+	}
+
+	pipeMs := pipeTotal.Seconds() * 1000 / float64(b.N)
+	ptyMs := ptyTotal.Seconds() * 1000 / float64(b.N)
+	b.ReportMetric(pipeMs, "pipe-ms/op")
+	b.ReportMetric(ptyMs, "pty-ms/op")
+	b.ReportMetric(pipeMs-ptyMs, "diff-ms/op")
+}
+
+func timeToFirstLineOverPipe(b *testing.B) time.Duration {
+	cmd := exec.Command(blockBufferedProgram[0], blockBufferedProgram[1:]...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		b.Fatalf("Failed to create stdout pipe: %v", err)
+	}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		b.Fatalf("Failed to start command: %v", err)
+	}
+
+	elapsed := readFirstLine(b, stdout, start)
+
+	if err := cmd.Wait(); err != nil {
+		b.Fatalf("Failed to wait for command: %v", err)
+	}
+	return elapsed
+}
+
+func timeToFirstLineOverPty(b *testing.B) time.Duration {
+	master, slave, err := openPty()
+	if err != nil {
+		b.Fatalf("Failed to allocate a PTY: %v", err)
+	}
+	defer master.Close()
+
+	cmd := exec.Command(blockBufferedProgram[0], blockBufferedProgram[1:]...)
+	cmd.Stdout = slave
+
+	start := time.Now()
+	err = cmd.Start()
+	// The child has its own copy now, the master gets EOF (EIO on Linux) once the child closes it.
+	slave.Close()
+	if err != nil {
+		b.Fatalf("Failed to start command: %v", err)
+	}
+
+	elapsed := readFirstLine(b, master, start)
+
+	if err := cmd.Wait(); err != nil {
+		b.Fatalf("Failed to wait for command: %v", err)
+	}
+	return elapsed
+}
+
+// readFirstLine reads the first line, returns the time it took since start and drains the rest of the output.
+func readFirstLine(b *testing.B, r io.Reader, start time.Time) time.Duration {
+	reader := bufio.NewReader(r)
+	if _, err := reader.ReadString('\n'); err != nil {
+		b.Fatalf("Failed to read the first line: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	// Reading from the PTY master fails with EIO once the slave is closed, which is the PTY way of saying EOF.
+	_, _ = io.Copy(io.Discard, reader)
+	return elapsed
+}
@@ -1,3 +1,4 @@
+using System.Diagnostics;
 using System.IO;
 using System.Threading;
 using System.Threading.Tasks;
@@ -104,6 +105,376 @@ public static partial class ChildProcess
         return await procHandle.WaitForExitAsync(cancellationToken);
     }
 
+    /// <summary>
+    /// Executes the process and hands its raw standard output to <paramref name="consumer"/> chunk by chunk. Waits for its completion.
+    /// </summary>
+    /// <param name="options">The process start options.</param>
+    /// <param name="consumer">The callback that receives every chunk read from the standard output, in the order it was written.</param>
+    /// <param name="timeout">The maximum time to wait for the process to exit.
+    /// When it elapses, the process is killed and what it has written up to that point is still handed to the consumer.</param>
+    /// <returns>The exit status of the process.</returns>
+    /// <remarks>
+    /// <para>
+    /// The consumer is invoked synchronously on the calling thread and the next read does not start until it returns, so a slow consumer slows down the child.
+    /// The chunk is a slice of a single buffer reused for all reads, so no memory is allocated per chunk. It must not escape the callback.
+    /// Chunk boundaries are arbitrary: they don't match lines or the writes of the child. Standard input and error are discarded.
+    /// </para>
+    /// <para>
+    /// When the consumer throws, the read end of the pipe is closed (so further writes of the child fail)
+    /// and the exception is rethrown once the process has exited.
+    /// </para>
+    /// </remarks>
+    public static ProcessExitStatus StreamOutput(ProcessStartOptions options, SpanConsumer consumer, TimeSpan? timeout = null)
+    {
+        ArgumentNullException.ThrowIfNull(options);
+        ArgumentNullException.ThrowIfNull(consumer);
+
+        TimeoutHelper timeoutHelper = TimeoutHelper.Start(timeout);
+        using SafeFileHandle nullHandle = File.OpenNullFileHandle();
+
+        // Same as for CaptureCombined: ASYNC read handle, so the read loop can stop on process exit or timeout.
+        File.CreatePipe(out SafeFileHandle read, out SafeFileHandle write, asyncRead: true);
+
+        using (read)
+        using (write)
+        using (SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, nullHandle, output: write, error: nullHandle))
+        {
+            int bytesRead = 0;
+            byte[] buffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+
+            try
+            {
+                bool timedOut;
+                try
+                {
+                    timedOut = Multiplexing.ReadCombinedOutputCore(read, processHandle, timeoutHelper, ref bytesRead, ref buffer, consumer);
+                }
+                catch
+                {
+                    // The consumer has thrown: closing the read end makes further writes of the child fail, so it does not block on a full pipe.
+                    read.Dispose();
+                    WaitForExit(processHandle, timeoutHelper);
+                    throw;
+                }
+
+                return timedOut
+                    ? WaitForExitOfKilledProcess(processHandle)
+                    : WaitForExit(processHandle, timeoutHelper);
+            }
+            finally
+            {
+                ArrayPool<byte>.Shared.Return(buffer);
+            }
+        }
+    }
+
+    /// <summary>
+    /// Executes the process and hands its raw standard output to <paramref name="consumer"/> chunk by chunk. Awaits for its completion.
+    /// </summary>
+    /// <param name="options">The process start options.</param>
+    /// <param name="consumer">The callback that receives every chunk read from the standard output, in the order it was written.</param>
+    /// <param name="cancellationToken">The cancellation token to cancel the operation.</param>
+    /// <returns>The exit status of the process.</returns>
+    /// <remarks>
+    /// Unlike <see cref="StreamOutput"/>, the consumer is invoked on a dedicated reader thread. The contract of the chunk is the same.
+    /// </remarks>
+    public static async Task<ProcessExitStatus> StreamOutputAsync(ProcessStartOptions options, SpanConsumer consumer, CancellationToken cancellationToken = default)
+    {
+        ArgumentNullException.ThrowIfNull(options);
+        ArgumentNullException.ThrowIfNull(consumer);
+
+        using SafeFileHandle nullHandle = File.OpenNullFileHandle();
+
+        // Synchronous reads: the consumer runs on the reader thread and the span never leaves its stack frame.
+        File.CreatePipe(out SafeFileHandle read, out SafeFileHandle write, asyncRead: false);
+
+        using (read)
+        {
+            SafeChildProcessHandle procHandle;
+            using (write)
+            {
+                procHandle = SafeChildProcessHandle.Start(options, nullHandle, write, nullHandle);
+            }
+
+            // Parent copy of the write end is closed now, so we get EOF when the child (and its descendants) close theirs.
+            using (procHandle)
+            {
+                // The reads block, so they get a dedicated thread rather than a thread pool one.
+                Task readTask = Task.Factory.StartNew(() => ReadToSpanConsumer(read, consumer), CancellationToken.None,
+                    TaskCreationOptions.LongRunning, TaskScheduler.Default);
+
+                ProcessExitStatus exitStatus;
+                try
+                {
+                    exitStatus = await procHandle.WaitForExitAsync(cancellationToken);
+                }
+                catch
+                {
+                    // Don't close the read end of the pipe while it's still being read.
+                    // The child is killed, so the reader is not blocked until it exits on its own.
+                    procHandle.KillCore(throwOnError: false);
+                    await readTask.ConfigureAwait(ConfigureAwaitOptions.SuppressThrowing);
+                    throw;
+                }
+
+                await readTask;
+                return exitStatus;
+            }
+        }
+    }
+
+    private static void ReadToSpanConsumer(SafeFileHandle read, SpanConsumer consumer)
+    {
+        using FileStream source = new(read, FileAccess.Read, bufferSize: 0, isAsync: false);
+
+        byte[] buffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+        try
+        {
+            int bytesRead;
+            while ((bytesRead = source.Read(buffer)) > 0)
+            {
+                consumer(buffer.AsSpan(0, bytesRead));
+            }
+        }
+        finally
+        {
+            ArrayPool<byte>.Shared.Return(buffer);
+        }
+    }
+
+    /// <summary>
+    /// Executes the process with STD OUT/ERR decoded and written to the specified writers. Waits for its completion.
+    /// </summary>
+    /// <param name="options">The process start options.</param>
+    /// <param name="outputWriter">The writer that receives the standard output, e.g. a <see cref="StringWriter"/>. If null, the output is discarded.</param>
+    /// <param name="errorWriter">The writer that receives the standard error. If null, the error is discarded.</param>
+    /// <param name="encoding">The encoding used to decode the output. If null, UTF-8 is used.</param>
+    /// <param name="timeout">The maximum time to wait for the process to exit.
+    /// When it elapses, the process is killed and what it has written up to that point is still written to the writers.</param>
+    /// <returns>The exit status of the process.</returns>
+    /// <remarks>
+    /// <para>
+    /// Line endings are normalized: every "\n" or "\r\n" written by the child is written as <see cref="TextWriter.NewLine"/> of the writer,
+    /// so the same output looks the same regardless of the platform of the child. Text after the last line ending is written as-is.
+    /// The writers are flushed at the end of the output, but never disposed. Standard input is discarded.
+    /// </para>
+    /// <para>
+    /// The writers are invoked synchronously on the calling thread. When the same writer is used for both streams,
+    /// the child writes both of them to a single pipe, so their relative order is preserved.
+    /// </para>
+    /// </remarks>
+    public static ProcessExitStatus RedirectToTextWriters(ProcessStartOptions options, TextWriter? outputWriter, TextWriter? errorWriter,
+        Encoding? encoding = null, TimeSpan? timeout = null)
+    {
+        ArgumentNullException.ThrowIfNull(options);
+
+        if (outputWriter is null && errorWriter is null)
+        {
+            return Discard(options, timeout);
+        }
+
+        encoding ??= Encoding.UTF8;
+        TimeoutHelper timeoutHelper = TimeoutHelper.Start(timeout);
+        using SafeFileHandle nullHandle = File.OpenNullFileHandle();
+
+        if (outputWriter is null || errorWriter is null || ReferenceEquals(outputWriter, errorWriter))
+        {
+            // A single writer: a single pipe, read by the same loop as CaptureCombined.
+            using TextWriterSink sink = new(outputWriter ?? errorWriter!, encoding);
+
+            File.CreatePipe(out SafeFileHandle read, out SafeFileHandle write, asyncRead: true);
+
+            using (read)
+            using (write)
+            using (SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, nullHandle,
+                output: outputWriter is null ? nullHandle : write, error: errorWriter is null ? nullHandle : write))
+            {
+                int bytesRead = 0;
+                byte[] buffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+
+                try
+                {
+                    bool timedOut;
+                    try
+                    {
+                        timedOut = Multiplexing.ReadCombinedOutputCore(read, processHandle, timeoutHelper, ref bytesRead, ref buffer, sink.Write);
+                        sink.Complete();
+                    }
+                    catch
+                    {
+                        // Same as for StreamOutput: the writer has thrown, further writes of the child fail.
+                        read.Dispose();
+                        WaitForExit(processHandle, timeoutHelper);
+                        throw;
+                    }
+
+                    return timedOut
+                        ? WaitForExitOfKilledProcess(processHandle)
+                        : WaitForExit(processHandle, timeoutHelper);
+                }
+                finally
+                {
+                    ArrayPool<byte>.Shared.Return(buffer);
+                }
+            }
+        }
+
+        using TextWriterSink outputSink = new(outputWriter, encoding);
+        using TextWriterSink errorSink = new(errorWriter, encoding);
+
+        // Same as for CaptureOutput: ASYNC read handles, multiplexed by a single loop.
+        File.CreatePipe(out SafeFileHandle readStdOut, out SafeFileHandle writeStdOut, asyncRead: true);
+        File.CreatePipe(out SafeFileHandle readStdErr, out SafeFileHandle writeStdErr, asyncRead: true);
+
+        using (readStdOut)
+        using (writeStdOut)
+        using (readStdErr)
+        using (writeStdErr)
+        using (SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, nullHandle, output: writeStdOut, error: writeStdErr))
+        {
+            int outputBytesRead = 0, errorBytesRead = 0;
+            byte[] outputBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+            byte[] errorBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+
+            try
+            {
+                bool timedOut;
+                try
+                {
+                    timedOut = Multiplexing.ReadProcessOutputCore(processHandle, readStdOut, readStdErr, timeoutHelper, int.MaxValue,
+                        ref outputBytesRead, ref errorBytesRead, ref outputBuffer, ref errorBuffer, outputSink.Write, errorSink.Write);
+                    outputSink.Complete();
+                    errorSink.Complete();
+                }
+                catch
+                {
+                    readStdOut.Dispose();
+                    readStdErr.Dispose();
+                    WaitForExit(processHandle, timeoutHelper);
+                    throw;
+                }
+
+                return timedOut
+                    ? WaitForExitOfKilledProcess(processHandle)
+                    : WaitForExit(processHandle, timeoutHelper);
+            }
+            finally
+            {
+                ArrayPool<byte>.Shared.Return(outputBuffer);
+                ArrayPool<byte>.Shared.Return(errorBuffer);
+            }
+        }
+    }
+
+    /// <summary>
+    /// Executes the process with STD OUT/ERR decoded and written to the specified writers. Awaits for its completion.
+    /// </summary>
+    /// <param name="options">The process start options.</param>
+    /// <param name="outputWriter">The writer that receives the standard output, e.g. a <see cref="StringWriter"/>. If null, the output is discarded.</param>
+    /// <param name="errorWriter">The writer that receives the standard error. If null, the error is discarded.</param>
+    /// <param name="encoding">The encoding used to decode the output. If null, UTF-8 is used.</param>
+    /// <param name="cancellationToken">The cancellation token to cancel the operation.</param>
+    /// <returns>The exit status of the process.</returns>
+    /// <remarks>
+    /// Unlike <see cref="RedirectToTextWriters"/>, every pipe is read by a dedicated reader thread that writes to its writer.
+    /// The rest of the behavior is the same.
+    /// </remarks>
+    public static async Task<ProcessExitStatus> RedirectToTextWritersAsync(ProcessStartOptions options, TextWriter? outputWriter, TextWriter? errorWriter,
+        Encoding? encoding = null, CancellationToken cancellationToken = default)
+    {
+        ArgumentNullException.ThrowIfNull(options);
+
+        if (outputWriter is null && errorWriter is null)
+        {
+            return await DiscardAsync(options, cancellationToken);
+        }
+
+        encoding ??= Encoding.UTF8;
+        // Same as for the synchronous overload: a single pipe for a single writer.
+        bool sameWriter = ReferenceEquals(outputWriter, errorWriter);
+        using SafeFileHandle nullHandle = File.OpenNullFileHandle();
+
+        SafeFileHandle? outputRead = null, outputWrite = null, errorRead = null, errorWrite = null;
+        try
+        {
+            if (outputWriter is not null)
+            {
+                File.CreatePipe(out outputRead, out outputWrite, asyncRead: false);
+            }
+            if (errorWriter is not null && !sameWriter)
+            {
+                File.CreatePipe(out errorRead, out errorWrite, asyncRead: false);
+            }
+
+            SafeChildProcessHandle procHandle;
+            try
+            {
+                procHandle = SafeChildProcessHandle.Start(options, nullHandle, outputWrite ?? nullHandle, sameWriter ? outputWrite! : errorWrite ?? nullHandle);
+            }
+            finally
+            {
+                // Parent copies of the write ends are closed, so we get EOF when the child (and its descendants) close theirs.
+                outputWrite?.Dispose();
+                errorWrite?.Dispose();
+            }
+
+            using (procHandle)
+            {
+                Task outputTask = outputRead is null
+                    ? Task.CompletedTask
+                    : Task.Factory.StartNew(() => ReadToTextWriter(outputRead, outputWriter!, encoding), CancellationToken.None,
+                        TaskCreationOptions.LongRunning, TaskScheduler.Default);
+                Task errorTask = errorRead is null
+                    ? Task.CompletedTask
+                    : Task.Factory.StartNew(() => ReadToTextWriter(errorRead, errorWriter!, encoding), CancellationToken.None,
+                        TaskCreationOptions.LongRunning, TaskScheduler.Default);
+
+                ProcessExitStatus exitStatus;
+                try
+                {
+                    exitStatus = await procHandle.WaitForExitAsync(cancellationToken);
+                }
+                catch
+                {
+                    // Same as for the span consumer: don't close the read ends while they are still being read.
+                    procHandle.KillCore(throwOnError: false);
+                    await Task.WhenAll(outputTask, errorTask).ConfigureAwait(ConfigureAwaitOptions.SuppressThrowing);
+                    throw;
+                }
+
+                await Task.WhenAll(outputTask, errorTask);
+                return exitStatus;
+            }
+        }
+        finally
+        {
+            outputRead?.Dispose();
+            errorRead?.Dispose();
+        }
+    }
+
+    private static void ReadToTextWriter(SafeFileHandle read, TextWriter writer, Encoding encoding)
+    {
+        using FileStream source = new(read, FileAccess.Read, bufferSize: 0, isAsync: false);
+        using TextWriterSink sink = new(writer, encoding);
+
+        byte[] buffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+        try
+        {
+            int bytesRead;
+            while ((bytesRead = source.Read(buffer)) > 0)
+            {
+                sink.Write(buffer.AsSpan(0, bytesRead));
+            }
+
+            sink.Complete();
+        }
+        finally
+        {
+            ArrayPool<byte>.Shared.Return(buffer);
+        }
+    }
+
     /// <summary>
     /// Executes the process with STD IN/OUT/ERR redirected to specified files. Waits for its completion.
     /// </summary>
@@ -123,6 +494,11 @@ public static partial class ChildProcess
 
         // NOTE: Since we accept file names, named pipes should work OOTB.
         // This will allow advanced users to implement more complex scenarios, but also fail into deadlocks if they don't consume the produced input!
+        if (options.StandardOutputToFileWithRotation is { } rotation && outputFile is not null)
+        {
+            return RedirectToRotatingFile(options, rotation, inputFile, outputFile, errorFile, timeout);
+        }
+
         var handles = OpenFileHandlesForRedirection(inputFile, outputFile, errorFile);
         using SafeFileHandle inputHandle = handles.input, outputHandle = handles.output, errorHandle = handles.error;
 
@@ -144,6 +520,11 @@ public static partial class ChildProcess
     {
         ArgumentNullException.ThrowIfNull(options);
 
+        if (options.StandardOutputToFileWithRotation is { } rotation && outputFile is not null)
+        {
+            return await RedirectToRotatingFileAsync(options, rotation, inputFile, outputFile, errorFile, cancellationToken);
+        }
+
         var handles = OpenFileHandlesForRedirection(inputFile, outputFile, errorFile);
         using SafeFileHandle inputHandle = handles.input, outputHandle = handles.output, errorHandle = handles.error;
 
@@ -151,6 +532,104 @@ public static partial class ChildProcess
         return await procHandle.WaitForExitAsync(cancellationToken);
     }
 
+    private static ProcessExitStatus RedirectToRotatingFile(ProcessStartOptions options, FileRotationOptions rotation,
+        string? inputFile, string outputFile, string? errorFile, TimeSpan? timeout)
+    {
+        // The child writes directly to the file descriptor, so it's not aware of the rotation.
+        // That is why the child writes to a pipe and we copy its content to the file, rotating it when needed.
+        bool errorToOutput = errorFile == outputFile;
+        var handles = OpenFileHandlesForRedirection(inputFile, outputFile: null, errorToOutput ? null : errorFile);
+        using SafeFileHandle inputHandle = handles.input, nullHandle = handles.output, errorHandle = handles.error;
+
+        TimeoutHelper timeoutHelper = TimeoutHelper.Start(timeout);
+
+        // Same as for CaptureCombined: ASYNC read handle, so the read loop can stop on process exit or timeout.
+        File.CreatePipe(out SafeFileHandle read, out SafeFileHandle write, asyncRead: true);
+
+        using (read)
+        using (write)
+        using (SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, inputHandle, write, errorToOutput ? write : errorHandle))
+        using (RotatingFileWriter destination = new(outputFile, rotation))
+        {
+            int bytesRead = 0;
+            byte[] buffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+
+            try
+            {
+                bool timedOut = Multiplexing.ReadCombinedOutputCore(read, processHandle, timeoutHelper, ref bytesRead, ref buffer, destination.Write);
+
+                return timedOut
+                    ? WaitForExitOfKilledProcess(processHandle)
+                    : WaitForExit(processHandle, timeoutHelper);
+            }
+            finally
+            {
+                ArrayPool<byte>.Shared.Return(buffer);
+            }
+        }
+    }
+
+    private static async Task<ProcessExitStatus> RedirectToRotatingFileAsync(ProcessStartOptions options, FileRotationOptions rotation,
+        string? inputFile, string outputFile, string? errorFile, CancellationToken cancellationToken)
+    {
+        // Same as for the synchronous version, but the content is copied by a thread pool task.
+        bool errorToOutput = errorFile == outputFile;
+        var handles = OpenFileHandlesForRedirection(inputFile, outputFile: null, errorToOutput ? null : errorFile);
+        using SafeFileHandle inputHandle = handles.input, nullHandle = handles.output, errorHandle = handles.error;
+
+        File.CreatePipe(out SafeFileHandle read, out SafeFileHandle write, asyncRead: OperatingSystem.IsWindows());
+
+        using (read)
+        {
+            SafeChildProcessHandle procHandle;
+            using (write)
+            {
+                procHandle = SafeChildProcessHandle.Start(options, inputHandle, write, errorToOutput ? write : errorHandle);
+            }
+
+            // Parent copy of the write end is closed now, so we get EOF when the child (and its descendants) close theirs.
+            using (procHandle)
+            {
+                Task copyTask = Task.Run(() => CopyToRotatingFileAsync(read, outputFile, rotation, cancellationToken), cancellationToken);
+
+                ProcessExitStatus exitStatus;
+                try
+                {
+                    exitStatus = await procHandle.WaitForExitAsync(cancellationToken);
+                }
+                catch
+                {
+                    // Don't close the read end of the pipe while it's still being copied.
+                    await copyTask.ConfigureAwait(ConfigureAwaitOptions.SuppressThrowing);
+                    throw;
+                }
+
+                await copyTask;
+                return exitStatus;
+            }
+        }
+    }
+
+    private static async Task CopyToRotatingFileAsync(SafeFileHandle read, string path, FileRotationOptions rotation, CancellationToken cancellationToken)
+    {
+        using Stream source = StreamHelper.CreateReadStream(read, cancellationToken);
+        using RotatingFileWriter destination = new(path, rotation);
+
+        byte[] buffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+        try
+        {
+            int bytesRead;
+            while ((bytesRead = await source.ReadAsync(buffer, cancellationToken)) > 0)
+            {
+                await destination.WriteAsync(buffer.AsMemory(0, bytesRead), cancellationToken);
+            }
+        }
+        finally
+        {
+            ArrayPool<byte>.Shared.Return(buffer);
+        }
+    }
+
     /// <summary>
     /// Creates an instance of <see cref="ProcessOutputLines"/> to stream the output of the process.
     /// </summary>
@@ -171,7 +650,7 @@ public static partial class ChildProcess
     /// <param name="encoding">The encoding to use when reading the output. If null, the default encoding is used (UTF-8).</param>
     /// <param name="input">An optional handle to a file that provides input to the process's standard input stream. If null, no input is provided.</param>
     /// <param name="timeout">An optional timeout that specifies the maximum duration to wait for the process to complete. If null, the
-    /// process will wait indefinitely.</param>
+    /// process will wait indefinitely. When it elapses, the process is killed and the result contains the output captured up to that point.</param>
     /// <returns>A <see cref="ProcessOutput" /> object containing the process's exit code, id, standard output and standard error data.</returns>
     /// <remarks>Use <see cref="Console.OpenStandardInputHandle()"/> to provide input of the process.</remarks>
     public static ProcessOutput CaptureOutput(ProcessStartOptions options, Encoding? encoding = null, SafeFileHandle? input = null, TimeSpan? timeout = null)
@@ -187,6 +666,8 @@ public static partial class ChildProcess
         File.CreatePipe(out readStdOut, out writeStdOut, asyncRead: true);
         File.CreatePipe(out readStdErr, out writeStdErr, asyncRead: true);
 
+        long startTimestamp = Stopwatch.GetTimestamp();
+
         using (readStdOut)
         using (writeStdOut)
         using (readStdErr)
@@ -195,25 +676,24 @@ public static partial class ChildProcess
         {
             int outputBytesRead = 0, errorBytesRead = 0;
 
-            byte[] outputBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
-            byte[] errorBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+            byte[] outputBuffer = ArrayPool<byte>.Shared.Rent(options.InitialOutputBufferSize);
+            byte[] errorBuffer = ArrayPool<byte>.Shared.Rent(options.InitialOutputBufferSize);
 
             try
             {
-                Multiplexing.ReadProcessOutputCore(processHandle, readStdOut, readStdErr, timeoutHelper,
+                bool timedOut = Multiplexing.ReadProcessOutputCore(processHandle, readStdOut, readStdErr, timeoutHelper, options.MaxOutputReadSize,
                     ref outputBytesRead, ref errorBytesRead, ref outputBuffer, ref errorBuffer);
 
-                TimeSpan remaining = timeoutHelper.GetRemaining();
-                var exitStatus = remaining == Timeout.InfiniteTimeSpan
-                    ? processHandle.WaitForExit()
-                    : processHandle.WaitForExitOrKillOnTimeout(remaining);
+                ProcessExitStatus exitStatus = timedOut
+                    ? WaitForExitOfKilledProcess(processHandle)
+                    : WaitForExit(processHandle, timeoutHelper);
 
                 // Instead of decoding on the fly, we decode once at the end.
                 encoding ??= Encoding.UTF8;
                 string output = encoding.GetString(outputBuffer, 0, outputBytesRead);
                 string error = encoding.GetString(errorBuffer, 0, errorBytesRead);
 
-                return new(exitStatus, output, error, processHandle.ProcessId);
+                return new(exitStatus, output, error, processHandle.ProcessId, options, Stopwatch.GetElapsedTime(startTimestamp));
             }
             finally
             {
@@ -242,6 +722,8 @@ public static partial class ChildProcess
         File.CreatePipe(out readStdOut, out writeStdOut, asyncRead: OperatingSystem.IsWindows());
         File.CreatePipe(out readStdErr, out writeStdErr, asyncRead: OperatingSystem.IsWindows());
 
+        long startTimestamp = Stopwatch.GetTimestamp();
+
         using (readStdOut)
         using (writeStdOut)
         using (readStdErr)
@@ -250,13 +732,14 @@ public static partial class ChildProcess
         using (Stream outputStream = StreamHelper.CreateReadStream(readStdOut, cancellationToken))
         using (Stream errorStream = StreamHelper.CreateReadStream(readStdErr, cancellationToken))
         {
-            byte[] outputBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
-            byte[] errorBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+            byte[] outputBuffer = ArrayPool<byte>.Shared.Rent(options.InitialOutputBufferSize);
+            byte[] errorBuffer = ArrayPool<byte>.Shared.Rent(options.InitialOutputBufferSize);
+            int maxReadSize = options.MaxOutputReadSize;
 
             int outputStartIndex = 0, errorStartIndex = 0;
 
-            Task<int> outputRead = outputStream.ReadAsync(outputBuffer, outputStartIndex, outputBuffer.Length - outputStartIndex, cancellationToken);
-            Task<int> errorRead = errorStream.ReadAsync(errorBuffer, errorStartIndex, errorBuffer.Length - errorStartIndex, cancellationToken);
+            Task<int> outputRead = outputStream.ReadAsync(outputBuffer, outputStartIndex, Math.Min(maxReadSize, outputBuffer.Length - outputStartIndex), cancellationToken);
+            Task<int> errorRead = errorStream.ReadAsync(errorBuffer, errorStartIndex, Math.Min(maxReadSize, errorBuffer.Length - errorStartIndex), cancellationToken);
 
             Task<int>[] tasks = [outputRead, errorRead];
 
@@ -278,7 +761,7 @@ public static partial class ChildProcess
                                 BufferHelper.RentLargerBuffer(ref errorBuffer);
                             }
                             // The tasks array may get resized, so we refer to error as last element.
-                            tasks[^1] = errorRead = errorStream.ReadAsync(errorBuffer, errorStartIndex, errorBuffer.Length - errorStartIndex, cancellationToken);
+                            tasks[^1] = errorRead = errorStream.ReadAsync(errorBuffer, errorStartIndex, Math.Min(maxReadSize, errorBuffer.Length - errorStartIndex), cancellationToken);
                         }
                         else
                         {
@@ -287,7 +770,7 @@ public static partial class ChildProcess
                             {
                                 BufferHelper.RentLargerBuffer(ref outputBuffer);
                             }
-                            tasks[0] = outputRead = outputStream.ReadAsync(outputBuffer, outputStartIndex, outputBuffer.Length - outputStartIndex, cancellationToken);
+                            tasks[0] = outputRead = outputStream.ReadAsync(outputBuffer, outputStartIndex, Math.Min(maxReadSize, outputBuffer.Length - outputStartIndex), cancellationToken);
                         }
                     }
                     else
@@ -315,7 +798,7 @@ public static partial class ChildProcess
                 string output = (encoding ?? Encoding.UTF8).GetString(outputBuffer, 0, outputStartIndex);
                 string error = (encoding ?? Encoding.UTF8).GetString(errorBuffer, 0, errorStartIndex);
 
-                return new(exitStatus, output, error, processHandle.ProcessId);
+                return new(exitStatus, output, error, processHandle.ProcessId, options, Stopwatch.GetElapsedTime(startTimestamp));
             }
             finally
             {
@@ -325,6 +808,137 @@ public static partial class ChildProcess
         }
     }
 
+    /// <summary>
+    /// Starts a process with the specified options and captures its standard output and error into seekable streams that can be read multiple times.
+    /// </summary>
+    /// <param name="options">The configuration options used to start the process. Cannot be null.</param>
+    /// <param name="input">An optional handle to a file that provides input to the process's standard input stream. If null, no input is provided.</param>
+    /// <param name="timeout">An optional timeout that specifies the maximum duration to wait for the process to complete. If null, the
+    /// process will wait indefinitely. When it elapses, the process is killed and the result contains the output captured up to that point.</param>
+    /// <returns>A <see cref="SeekableProcessOutput" /> object containing the process's exit status, id, standard output and error. It must be disposed.</returns>
+    /// <remarks>
+    /// Each stream is kept in memory until it exceeds <see cref="ProcessStartOptions.CapturedOutputSpillThresholdBytes"/>,
+    /// then it's moved to a temporary file.
+    /// </remarks>
+    public static SeekableProcessOutput CaptureSeekableOutput(ProcessStartOptions options, SafeFileHandle? input = null, TimeSpan? timeout = null)
+    {
+        ArgumentNullException.ThrowIfNull(options);
+
+        TimeoutHelper timeoutHelper = TimeoutHelper.Start(timeout);
+
+        // Same as for CaptureOutput: ASYNC read handles, multiplexed by a single loop.
+        File.CreatePipe(out SafeFileHandle readStdOut, out SafeFileHandle writeStdOut, asyncRead: true);
+        File.CreatePipe(out SafeFileHandle readStdErr, out SafeFileHandle writeStdErr, asyncRead: true);
+
+        using (readStdOut)
+        using (writeStdOut)
+        using (readStdErr)
+        using (writeStdErr)
+        using (SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input, output: writeStdOut, error: writeStdErr))
+        using (SpillableStreamWriter output = new(options.CapturedOutputSpillThresholdBytes))
+        using (SpillableStreamWriter error = new(options.CapturedOutputSpillThresholdBytes))
+        {
+            int outputBytesRead = 0, errorBytesRead = 0;
+            byte[] outputBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+            byte[] errorBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+
+            try
+            {
+                // Every chunk is copied to its stream as soon as it's read, so the buffers don't grow with the output.
+                bool timedOut = Multiplexing.ReadProcessOutputCore(processHandle, readStdOut, readStdErr, timeoutHelper, int.MaxValue,
+                    ref outputBytesRead, ref errorBytesRead, ref outputBuffer, ref errorBuffer, output.Write, error.Write);
+
+                ProcessExitStatus exitStatus = timedOut
+                    ? WaitForExitOfKilledProcess(processHandle)
+                    : WaitForExit(processHandle, timeoutHelper);
+
+                return new SeekableProcessOutput(exitStatus, output.Complete(), error.Complete(), processHandle.ProcessId);
+            }
+            finally
+            {
+                ArrayPool<byte>.Shared.Return(outputBuffer);
+                ArrayPool<byte>.Shared.Return(errorBuffer);
+            }
+        }
+    }
+
+    /// <summary>
+    /// Starts a process with the specified options and captures its standard output and error into seekable streams that can be read multiple times.
+    /// </summary>
+    /// <param name="options">The configuration options used to start the process. Cannot be null.</param>
+    /// <param name="input">An optional handle to a file that provides input to the process's standard input stream. If null, no input is provided.</param>
+    /// <param name="cancellationToken">A cancellation token to cancel the operation.</param>
+    /// <returns>A <see cref="SeekableProcessOutput" /> object containing the process's exit status, id, standard output and error. It must be disposed.</returns>
+    /// <remarks>
+    /// Each stream is kept in memory until it exceeds <see cref="ProcessStartOptions.CapturedOutputSpillThresholdBytes"/>,
+    /// then it's moved to a temporary file.
+    /// </remarks>
+    public static async Task<SeekableProcessOutput> CaptureSeekableOutputAsync(ProcessStartOptions options, SafeFileHandle? input = null, CancellationToken cancellationToken = default)
+    {
+        ArgumentNullException.ThrowIfNull(options);
+
+        using SafeFileHandle nullHandle = File.OpenNullFileHandle();
+
+        // Same as for the span consumer: synchronous reads on dedicated threads, so the output is copied without any extra buffering.
+        File.CreatePipe(out SafeFileHandle readStdOut, out SafeFileHandle writeStdOut, asyncRead: false);
+        File.CreatePipe(out SafeFileHandle readStdErr, out SafeFileHandle writeStdErr, asyncRead: false);
+
+        using (readStdOut)
+        using (readStdErr)
+        using (SpillableStreamWriter output = new(options.CapturedOutputSpillThresholdBytes))
+        using (SpillableStreamWriter error = new(options.CapturedOutputSpillThresholdBytes))
+        {
+            SafeChildProcessHandle procHandle;
+            using (writeStdOut)
+            using (writeStdErr)
+            {
+                procHandle = SafeChildProcessHandle.Start(options, input ?? nullHandle, writeStdOut, writeStdErr);
+            }
+
+            using (procHandle)
+            {
+                Task outputTask = Task.Factory.StartNew(() => ReadToSpillableStream(readStdOut, output), CancellationToken.None,
+                    TaskCreationOptions.LongRunning, TaskScheduler.Default);
+                Task errorTask = Task.Factory.StartNew(() => ReadToSpillableStream(readStdErr, error), CancellationToken.None,
+                    TaskCreationOptions.LongRunning, TaskScheduler.Default);
+
+                ProcessExitStatus exitStatus;
+                try
+                {
+                    exitStatus = await procHandle.WaitForExitAsync(cancellationToken);
+                }
+                catch
+                {
+                    procHandle.KillCore(throwOnError: false);
+                    await Task.WhenAll(outputTask, errorTask).ConfigureAwait(ConfigureAwaitOptions.SuppressThrowing);
+                    throw;
+                }
+
+                await Task.WhenAll(outputTask, errorTask);
+                return new SeekableProcessOutput(exitStatus, output.Complete(), error.Complete(), procHandle.ProcessId);
+            }
+        }
+    }
+
+    private static void ReadToSpillableStream(SafeFileHandle read, SpillableStreamWriter destination)
+    {
+        using FileStream source = new(read, FileAccess.Read, bufferSize: 0, isAsync: false);
+
+        byte[] buffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+        try
+        {
+            int bytesRead;
+            while ((bytesRead = source.Read(buffer)) > 0)
+            {
+                destination.Write(buffer.AsSpan(0, bytesRead));
+            }
+        }
+        finally
+        {
+            ArrayPool<byte>.Shared.Return(buffer);
+        }
+    }
+
     /// <summary>
     /// Starts a process with the specified options and returns the combined output, including both standard output and
     /// standard error streams.
@@ -332,7 +946,7 @@ public static partial class ChildProcess
     /// <param name="options">The configuration options used to start the process. Cannot be null.</param>
     /// <param name="input">An optional handle to a file that provides input to the process's standard input stream. If null, no input is provided.</param>
     /// <param name="timeout">An optional timeout that specifies the maximum duration to wait for the process to complete. If null, the
-    /// process will wait indefinitely.</param>
+    /// process will wait indefinitely. When it elapses, the process is killed and the result contains the output captured up to that point.</param>
     /// <returns>A <see cref="CombinedOutput" /> object containing the process's exit code, id, standard output and standard error data.</returns>
     /// <remarks>Use <see cref="Console.OpenStandardInput()"/> to provide input of the process.</remarks>
     public static CombinedOutput CaptureCombined(ProcessStartOptions options, SafeFileHandle? input = null, TimeSpan? timeout = null)
@@ -353,12 +967,11 @@ public static partial class ChildProcess
 
             try
             {
-                Multiplexing.ReadCombinedOutputCore(read, processHandle, timeoutHelper, ref totalBytesRead, ref buffer);
+                bool timedOut = Multiplexing.ReadCombinedOutputCore(read, processHandle, timeoutHelper, ref totalBytesRead, ref buffer);
 
-                TimeSpan remaining = timeoutHelper.GetRemaining();
-                var exitStatus = remaining == Timeout.InfiniteTimeSpan
-                    ? processHandle.WaitForExit()
-                    : processHandle.WaitForExitOrKillOnTimeout(remaining);
+                ProcessExitStatus exitStatus = timedOut
+                    ? WaitForExitOfKilledProcess(processHandle)
+                    : WaitForExit(processHandle, timeoutHelper);
 
                 byte[] resultBuffer = BufferHelper.CreateCopy(buffer, totalBytesRead);
                 return new(exitStatus, resultBuffer, processHandle.ProcessId);
@@ -432,6 +1045,21 @@ public static partial class ChildProcess
         }
     }
 
+    private static ProcessExitStatus WaitForExit(SafeChildProcessHandle processHandle, TimeoutHelper timeoutHelper)
+    {
+        TimeSpan remaining = timeoutHelper.GetRemaining();
+        return remaining == Timeout.InfiniteTimeSpan
+            ? processHandle.WaitForExit()
+            : processHandle.WaitForExitOrKillOnTimeout(remaining);
+    }
+
+    // The reading has timed out, so the process was killed and its output drained up to that point.
+    private static ProcessExitStatus WaitForExitOfKilledProcess(SafeChildProcessHandle processHandle)
+    {
+        ProcessExitStatus exitStatus = processHandle.WaitForExit();
+        return new ProcessExitStatus(exitStatus.ExitCode, cancelled: true, exitStatus.Signal);
+    }
+
     private static (SafeFileHandle input, SafeFileHandle output, SafeFileHandle error) OpenFileHandlesForRedirection(string? inputFile, string? outputFile, string? errorFile)
     {
         SafeFileHandle inputHandle = inputFile switch
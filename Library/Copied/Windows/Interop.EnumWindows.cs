@@ -0,0 +1,29 @@
+// Licensed to the .NET Foundation under one or more agreements.
+// The .NET Foundation licenses this file to you under the MIT license.
+
+using System;
+using System.Runtime.InteropServices;
+
+internal static partial class Interop
+{
+    internal static partial class User32
+    {
+        internal const int GW_OWNER = 4;
+        internal const int WM_CLOSE = 0x0010;
+
+        [LibraryImport(Libraries.User32)]
+        public static unsafe partial Interop.BOOL EnumWindows(delegate* unmanaged<IntPtr, IntPtr, Interop.BOOL> callback, IntPtr extraData);
+
+        [LibraryImport(Libraries.User32)]
+        public static unsafe partial int GetWindowThreadProcessId(IntPtr handle, int* processId);
+
+        [LibraryImport(Libraries.User32)]
+        public static partial IntPtr GetWindow(IntPtr hWnd, int uCmd);
+
+        [LibraryImport(Libraries.User32)]
+        public static partial Interop.BOOL IsWindowVisible(IntPtr hWnd);
+
+        [LibraryImport(Libraries.User32, EntryPoint = "PostMessageW", SetLastError = true)]
+        public static partial Interop.BOOL PostMessage(IntPtr hWnd, int msg, IntPtr wParam, IntPtr lParam);
+    }
+}
@@ -26,6 +26,7 @@ internal static partial class Interop
         internal enum JOBOBJECTINFOCLASS
         {
             JobObjectBasicLimitInformation = 2,
+            JobObjectBasicProcessIdList = 3,
             JobObjectExtendedLimitInformation = 9
         }
 
@@ -74,5 +75,9 @@ internal static partial class Interop
         [LibraryImport(Libraries.Kernel32, SetLastError = true)]
         [return: MarshalAs(UnmanagedType.Bool)]
         internal static partial bool TerminateJobObject(IntPtr hJob, uint uExitCode);
+
+        [LibraryImport(Libraries.Kernel32, SetLastError = true)]
+        [return: MarshalAs(UnmanagedType.Bool)]
+        internal static unsafe partial bool QueryInformationJobObject(IntPtr hJob, JOBOBJECTINFOCLASS JobObjectInfoClass, void* lpJobObjectInfo, uint cbJobObjectInfoLength, IntPtr lpReturnLength);
     }
 }
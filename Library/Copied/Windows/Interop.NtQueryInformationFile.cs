@@ -0,0 +1,23 @@
+// Licensed to the .NET Foundation under one or more agreements.
+// The .NET Foundation licenses this file to you under the MIT license.
+
+using System;
+using System.Runtime.InteropServices;
+using Microsoft.Win32.SafeHandles;
+
+internal static partial class Interop
+{
+    internal static partial class NtDll
+    {
+        [StructLayout(LayoutKind.Sequential)]
+        internal struct IO_STATUS_BLOCK
+        {
+            // It's a union of NTSTATUS and PVOID, so it's pointer-sized.
+            internal IntPtr Status;
+            internal IntPtr Information;
+        }
+
+        [LibraryImport(Libraries.NtDll)]
+        internal static unsafe partial int NtQueryInformationFile(SafeFileHandle FileHandle, out IO_STATUS_BLOCK IoStatusBlock, void* FileInformation, uint Length, uint FileInformationClass);
+    }
+}
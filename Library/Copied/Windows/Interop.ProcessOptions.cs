@@ -31,6 +31,16 @@ internal static partial class Interop
             internal const int SYNCHRONIZE = 0x00100000;
         }
 
+        internal static partial class PriorityClass
+        {
+            internal const int IDLE_PRIORITY_CLASS = 0x00000040;
+            internal const int BELOW_NORMAL_PRIORITY_CLASS = 0x00004000;
+            internal const int NORMAL_PRIORITY_CLASS = 0x00000020;
+            internal const int ABOVE_NORMAL_PRIORITY_CLASS = 0x00008000;
+            internal const int HIGH_PRIORITY_CLASS = 0x00000080;
+            internal const int REALTIME_PRIORITY_CLASS = 0x00000100;
+        }
+
         internal static partial class RPCStatus
         {
             internal const int RPC_S_SERVER_UNAVAILABLE = 1722;
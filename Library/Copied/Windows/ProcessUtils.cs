@@ -7,9 +7,7 @@ internal static class ProcessUtils
 {
     internal static void BuildArgs(ProcessStartOptions options, ref ValueStringBuilder applicationName, ref ValueStringBuilder commandLine)
     {
-        string absolutePath = options.IsFileNameResolved
-            ? options.FileName
-            : ProcessStartOptions.ResolvePathInternal(options.FileName);
+        string absolutePath = options.GetResolvedFileName();
 
         applicationName.Append(absolutePath);
         applicationName.NullTerminate();
@@ -35,7 +33,13 @@ internal static class ProcessUtils
 
         var keys = new string[sd.Count];
         sd.Keys.CopyTo(keys, 0);
-        Array.Sort(keys, StringComparer.OrdinalIgnoreCase);
+        // Names that differ only by case are possible when the dictionary is case-sensitive
+        // (see ProcessStartOptions.EnvironmentCaseSensitivityOverride), sort them ordinally to keep the block deterministic.
+        Array.Sort(keys, static (x, y) =>
+        {
+            int result = StringComparer.OrdinalIgnoreCase.Compare(x, y);
+            return result != 0 ? result : StringComparer.Ordinal.Compare(x, y);
+        });
 
         // Join the null-terminated "key=val\0" strings
         var result = new StringBuilder(8 * keys.Length);
@@ -0,0 +1,16 @@
+namespace System.TBA;
+
+/// <summary>
+/// Specifies how environment variable names are compared.
+/// </summary>
+public enum EnvironmentCaseSensitivity
+{
+    /// <summary>Names are case-insensitive on Windows and case-sensitive on Unix.</summary>
+    PlatformDefault,
+
+    /// <summary>Names are always compared case-sensitively.</summary>
+    CaseSensitive,
+
+    /// <summary>Names are always compared case-insensitively.</summary>
+    CaseInsensitive,
+}
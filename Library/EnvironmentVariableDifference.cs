@@ -0,0 +1,56 @@
+namespace System.TBA;
+
+/// <summary>
+/// Describes an environment variable whose value differs between the child process and the current process.
+/// </summary>
+public readonly struct EnvironmentVariableDifference : IEquatable<EnvironmentVariableDifference>
+{
+    // Design: ctor is public to allow for mocking in tests.
+    public EnvironmentVariableDifference(string name, string? parentValue, string? childValue)
+    {
+        ArgumentException.ThrowIfNullOrEmpty(name);
+
+        Name = name;
+        ParentValue = parentValue;
+        ChildValue = childValue;
+    }
+
+    /// <summary>
+    /// Gets the name of the environment variable.
+    /// </summary>
+    public string Name { get; }
+
+    /// <summary>
+    /// Gets the value in the current process, or null if the variable is not defined there.
+    /// </summary>
+    public string? ParentValue { get; }
+
+    /// <summary>
+    /// Gets the value the child process is going to get, or null if the variable is removed.
+    /// </summary>
+    public string? ChildValue { get; }
+
+    /// <summary>
+    /// Gets a value indicating whether the variable is defined only for the child process.
+    /// </summary>
+    public bool IsAdded => ParentValue is null && ChildValue is not null;
+
+    /// <summary>
+    /// Gets a value indicating whether the variable is defined only in the current process.
+    /// </summary>
+    public bool IsRemoved => ParentValue is not null && ChildValue is null;
+
+    /// <summary>
+    /// Gets a value indicating whether the variable is defined in both processes, with different values.
+    /// </summary>
+    public bool IsChanged => ParentValue is not null && ChildValue is not null;
+
+    public bool Equals(EnvironmentVariableDifference other)
+        => Name == other.Name && ParentValue == other.ParentValue && ChildValue == other.ChildValue;
+
+    public override bool Equals(object? obj) => obj is EnvironmentVariableDifference other && Equals(other);
+
+    public override int GetHashCode() => HashCode.Combine(Name, ParentValue, ChildValue);
+
+    public override string ToString() => $"{Name}: {ParentValue ?? "<null>"} -> {ChildValue ?? "<null>"}";
+}
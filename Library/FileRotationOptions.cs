@@ -0,0 +1,31 @@
+namespace System.TBA;
+
+/// <summary>
+/// Describes a size-based rotation policy of an output file.
+/// </summary>
+/// <remarks>
+/// When the file would grow beyond <see cref="MaxFileSize"/>, it's closed and renamed to "{path}.1",
+/// previously rotated files are shifted ("{path}.1" becomes "{path}.2" and so on) and a fresh file is opened.
+/// Files that exceed <see cref="MaxRotatedFiles"/> are deleted.
+/// </remarks>
+public sealed class FileRotationOptions
+{
+    public FileRotationOptions(long maxFileSize, int maxRotatedFiles)
+    {
+        ArgumentOutOfRangeException.ThrowIfNegativeOrZero(maxFileSize);
+        ArgumentOutOfRangeException.ThrowIfNegative(maxRotatedFiles);
+
+        MaxFileSize = maxFileSize;
+        MaxRotatedFiles = maxRotatedFiles;
+    }
+
+    /// <summary>
+    /// Gets the maximum size of the file in bytes.
+    /// </summary>
+    public long MaxFileSize { get; }
+
+    /// <summary>
+    /// Gets the maximum number of rotated files to keep. When it's zero, the file is truncated instead of renamed.
+    /// </summary>
+    public int MaxRotatedFiles { get; }
+}
@@ -14,6 +14,9 @@ internal static class BufferHelper
         4096 * 8;
 #endif
 
+    // Small reads, so every chunk of text is delivered as soon as possible in unbuffered mode.
+    internal const int UnbufferedReadSize = 128;
+
     internal static void RentLargerBuffer(ref byte[] buffer)
     {
         byte[] oldBuffer = buffer;
@@ -29,6 +32,16 @@ internal static class BufferHelper
         }
     }
 
+    // Hands the bytes read so far to the consumer (if any), so the next read reuses the buffer instead of growing it.
+    internal static void FlushToConsumer(SpanConsumer? consumer, byte[] buffer, ref int bytesRead)
+    {
+        if (consumer is not null && bytesRead > 0)
+        {
+            consumer(buffer.AsSpan(0, bytesRead));
+            bytesRead = 0;
+        }
+    }
+
     internal static byte[] CreateCopy(byte[] buffer, int totalBytesRead)
     {
         byte[] resultBuffer = GC.AllocateUninitializedArray<byte>(totalBytesRead);
@@ -0,0 +1,50 @@
+using System.Collections.Concurrent;
+using System.Diagnostics;
+using System.IO;
+
+namespace System.TBA;
+
+/// <summary>
+/// Process-wide cache of resolved executable paths used when <see cref="ProcessStartOptions.FileNameResolutionCacheTimeToLive"/> is set.
+/// </summary>
+internal static class FileNameResolutionCache
+{
+    // The cache is meant for hot loops that launch a handful of commands, so we keep it small and simply start over when it's full.
+    private const int MaxEntryCount = 64;
+
+    private static readonly ConcurrentDictionary<(string FileName, string? PathEnvVar, string CurrentDirectory), (string ResolvedPath, long Timestamp)> s_entries = new();
+
+    internal static string Resolve(string fileName, TimeSpan timeToLive)
+    {
+        // The resolution depends on PATH and the current directory too, a change of any of them is a miss.
+        (string, string?, string) key = (fileName, Environment.GetEnvironmentVariable("PATH"), Directory.GetCurrentDirectory());
+
+        if (s_entries.TryGetValue(key, out (string ResolvedPath, long Timestamp) entry)
+            && Stopwatch.GetElapsedTime(entry.Timestamp) < timeToLive)
+        {
+            return entry.ResolvedPath;
+        }
+
+        string resolvedPath = ProcessStartOptions.ResolvePathInternal(fileName);
+
+        if (s_entries.Count >= MaxEntryCount)
+        {
+            s_entries.Clear();
+        }
+        s_entries[key] = (resolvedPath, Stopwatch.GetTimestamp());
+
+        return resolvedPath;
+    }
+
+    // Called when the cached path could not be started (e.g. the file was removed), so the next launch resolves it again.
+    internal static void Invalidate(string fileName)
+    {
+        foreach ((string FileName, string? PathEnvVar, string CurrentDirectory) key in s_entries.Keys)
+        {
+            if (key.FileName == fileName)
+            {
+                s_entries.TryRemove(key, out _);
+            }
+        }
+    }
+}
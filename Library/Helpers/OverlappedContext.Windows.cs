@@ -77,7 +77,8 @@ internal sealed unsafe class OverlappedContext : IDisposable
         return bytesRead;
     }
 
-    internal void CancelPendingIO(SafeFileHandle handle)
+    // Returns the number of bytes read by the operation if it has completed before it could be canceled.
+    internal int CancelPendingIO(SafeFileHandle handle)
     {
         // CancelIoEx marks matching outstanding I/O requests for cancellation.
         // It does not wait for all canceled operations to complete.
@@ -102,10 +103,49 @@ internal sealed unsafe class OverlappedContext : IDisposable
         {
             int errorCode = Marshal.GetLastPInvokeError();
             Debug.Assert(errorCode is Interop.Errors.ERROR_OPERATION_ABORTED or Interop.Errors.ERROR_BROKEN_PIPE, $"GetOverlappedResult failed with {errorCode}.");
+            bytesRead = 0;
         }
-        Debug.Assert(bytesRead == 0, $"Expected zero bytes read after cancellation, got {bytesRead}.");
 
-        handle.Close();
+        return bytesRead;
+    }
+
+    // Reads what is already buffered in the pipe once the pending I/O has been canceled.
+    // It's bounded by the number of bytes available when the drain starts,
+    // so a grandchild process that keeps the write end open can't make it read forever.
+    internal void DrainPipe(SafeFileHandle handle, ref byte[] buffer, ref int totalBytesRead, int maxReadSize = int.MaxValue)
+    {
+        int bytesAvailable = 0;
+        if (!Interop.Kernel32.PeekNamedPipe(handle, null, 0, null, &bytesAvailable, null))
+        {
+            return; // EOF or broken pipe, nothing left to read.
+        }
+
+        while (bytesAvailable > 0)
+        {
+            if (totalBytesRead == buffer.Length)
+            {
+                BufferHelper.RentLargerBuffer(ref buffer);
+            }
+
+            int bytesRead;
+            int requested = Math.Min(bytesAvailable, Math.Min(maxReadSize, buffer.Length - totalBytesRead));
+            fixed (byte* pinned = &buffer[totalBytesRead])
+            {
+                Interop.Kernel32.ReadFile(handle, pinned, requested, IntPtr.Zero, Reset());
+
+                // The data is already in the pipe, so the read does not wait for the writer.
+                _waitHandle.WaitOne();
+                bytesRead = GetOverlappedResult(handle);
+            }
+
+            if (bytesRead <= 0)
+            {
+                return; // EOF
+            }
+
+            totalBytesRead += bytesRead;
+            bytesAvailable -= bytesRead;
+        }
     }
 
     private NativeOverlapped* Reset()
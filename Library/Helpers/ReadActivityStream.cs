@@ -0,0 +1,73 @@
+using System.IO;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace System.TBA;
+
+/// <summary>
+/// This class exists so we can observe every chunk of bytes that arrives, even when the consumer reads whole lines.
+/// </summary>
+internal sealed class ReadActivityStream : Stream
+{
+    private readonly Stream _inner;
+    private readonly Action<int> _onBytesRead;
+
+    internal ReadActivityStream(Stream inner, Action<int> onBytesRead)
+    {
+        _inner = inner;
+        _onBytesRead = onBytesRead;
+    }
+
+    protected override void Dispose(bool disposing)
+    {
+        try
+        {
+            if (disposing)
+            {
+                _inner.Dispose();
+            }
+        }
+        finally
+        {
+            base.Dispose(disposing);
+        }
+    }
+
+    public override bool CanRead => true;
+
+    public override bool CanSeek => false;
+
+    public override bool CanWrite => false;
+
+    public override long Length => throw new NotSupportedException();
+
+    public override long Position { get => throw new NotSupportedException(); set => throw new NotSupportedException(); }
+
+    public override int Read(byte[] buffer, int offset, int count) => OnRead(_inner.Read(buffer, offset, count));
+
+    public override int Read(Span<byte> buffer) => OnRead(_inner.Read(buffer));
+
+    public override Task<int> ReadAsync(byte[] buffer, int offset, int count, CancellationToken cancellationToken)
+        => ReadAsync(new Memory<byte>(buffer, offset, count), cancellationToken).AsTask();
+
+    public override async ValueTask<int> ReadAsync(Memory<byte> buffer, CancellationToken cancellationToken = default)
+        => OnRead(await _inner.ReadAsync(buffer, cancellationToken));
+
+    public override void Flush() => throw new NotSupportedException();
+
+    public override long Seek(long offset, SeekOrigin origin) => throw new NotSupportedException();
+
+    public override void SetLength(long value) => throw new NotSupportedException();
+
+    public override void Write(byte[] buffer, int offset, int count) => throw new NotSupportedException();
+
+    private int OnRead(int bytesRead)
+    {
+        if (bytesRead > 0)
+        {
+            _onBytesRead(bytesRead);
+        }
+
+        return bytesRead;
+    }
+}
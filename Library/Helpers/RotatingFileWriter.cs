@@ -0,0 +1,86 @@
+using System.IO;
+using System.Threading;
+using System.Threading.Tasks;
+
+namespace System.TBA;
+
+internal sealed class RotatingFileWriter : IDisposable
+{
+    private readonly string _path;
+    private readonly FileRotationOptions _rotation;
+    private FileStream _stream;
+
+    internal RotatingFileWriter(string path, FileRotationOptions rotation)
+    {
+        _path = path;
+        _rotation = rotation;
+        // Append, so restarts of a long-running child keep adding to the current file.
+        _stream = Open(path);
+    }
+
+    // It's a SpanConsumer, so it can be handed to the multiplexing loop directly.
+    internal void Write(ReadOnlySpan<byte> data)
+    {
+        while (!data.IsEmpty)
+        {
+            int toWrite = GetWritableLength(data.Length);
+            _stream.Write(data.Slice(0, toWrite));
+            data = data.Slice(toWrite);
+        }
+    }
+
+    internal async Task WriteAsync(ReadOnlyMemory<byte> data, CancellationToken cancellationToken)
+    {
+        while (!data.IsEmpty)
+        {
+            int toWrite = GetWritableLength(data.Length);
+            await _stream.WriteAsync(data.Slice(0, toWrite), cancellationToken);
+            data = data.Slice(toWrite);
+        }
+    }
+
+    public void Dispose() => _stream.Dispose();
+
+    // Rotates the file when it's full and returns how many of the bytes fit into the current one.
+    private int GetWritableLength(int length)
+    {
+        long available = _rotation.MaxFileSize - _stream.Length;
+        if (available <= 0)
+        {
+            Rotate();
+            available = _rotation.MaxFileSize;
+        }
+
+        return (int)Math.Min(available, length);
+    }
+
+    private void Rotate()
+    {
+        _stream.Dispose();
+
+        if (_rotation.MaxRotatedFiles == 0)
+        {
+            File.Delete(_path);
+        }
+        else
+        {
+            File.Delete(GetRotatedPath(_rotation.MaxRotatedFiles));
+            for (int i = _rotation.MaxRotatedFiles - 1; i >= 1; i--)
+            {
+                string rotatedPath = GetRotatedPath(i);
+                if (File.Exists(rotatedPath))
+                {
+                    File.Move(rotatedPath, GetRotatedPath(i + 1));
+                }
+            }
+            File.Move(_path, GetRotatedPath(1));
+        }
+
+        _stream = Open(_path);
+    }
+
+    private string GetRotatedPath(int index) => $"{_path}.{index}";
+
+    private static FileStream Open(string path)
+        => new(path, FileMode.Append, FileAccess.Write, FileShare.ReadWrite | FileShare.Delete, bufferSize: 0);
+}
@@ -0,0 +1,51 @@
+using System.IO;
+
+namespace System.TBA;
+
+// Collects the output of a single stream in memory and moves it to a temporary file once it exceeds the threshold.
+internal sealed class SpillableStreamWriter : IDisposable
+{
+    private readonly long? _spillThreshold;
+    private Stream? _destination = new MemoryStream();
+
+    internal SpillableStreamWriter(long? spillThreshold) => _spillThreshold = spillThreshold;
+
+    // It's a SpanConsumer, so it can be handed to the multiplexing loops directly.
+    internal void Write(ReadOnlySpan<byte> bytes)
+    {
+        Stream destination = _destination!;
+        if (destination is MemoryStream memory && _spillThreshold is long threshold && memory.Length + bytes.Length > threshold)
+        {
+            _destination = destination = SpillToTemporaryFile(memory);
+        }
+        destination.Write(bytes);
+    }
+
+    // Rewinds the stream and transfers its ownership to the caller.
+    internal Stream Complete()
+    {
+        Stream destination = _destination!;
+        _destination = null;
+        destination.Position = 0;
+        return destination;
+    }
+
+    public void Dispose() => _destination?.Dispose();
+
+    private static FileStream SpillToTemporaryFile(MemoryStream memory)
+    {
+        // DeleteOnClose: the file goes away with the stream, even when the caller never reads it.
+        FileStream file = new(Path.Combine(Path.GetTempPath(), Path.GetRandomFileName()), FileMode.CreateNew, FileAccess.ReadWrite, FileShare.None,
+            bufferSize: 4096, FileOptions.DeleteOnClose);
+        try
+        {
+            memory.WriteTo(file);
+        }
+        catch
+        {
+            file.Dispose();
+            throw;
+        }
+        return file;
+    }
+}
@@ -0,0 +1,81 @@
+using System.Buffers;
+using System.IO;
+using System.Text;
+
+namespace System.TBA;
+
+// Decodes the raw output of a single stream and writes it to a TextWriter,
+// normalizing every "\n" or "\r\n" to the NewLine of the writer.
+internal sealed class TextWriterSink : IDisposable
+{
+    private readonly TextWriter _writer;
+    private readonly Decoder _decoder;
+    private readonly char[] _chars;
+    private bool _pendingCarriageReturn;
+
+    internal TextWriterSink(TextWriter writer, Encoding encoding)
+    {
+        _writer = writer;
+        _decoder = encoding.GetDecoder();
+        _chars = ArrayPool<char>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
+    }
+
+    // It's a SpanConsumer, so it can be handed to the multiplexing loops directly.
+    internal void Write(ReadOnlySpan<byte> bytes)
+    {
+        while (!bytes.IsEmpty)
+        {
+            _decoder.Convert(bytes, _chars, flush: false, out int bytesUsed, out int charsUsed, out _);
+            WriteWithNormalizedLineEndings(_chars.AsSpan(0, charsUsed));
+            bytes = bytes.Slice(bytesUsed);
+        }
+    }
+
+    // Called at the end of the output: writes what the decoder and the line ending normalization hold back and flushes the writer.
+    internal void Complete()
+    {
+        int remaining = _decoder.GetChars(ReadOnlySpan<byte>.Empty, _chars, flush: true);
+        WriteWithNormalizedLineEndings(_chars.AsSpan(0, remaining));
+        if (_pendingCarriageReturn)
+        {
+            _pendingCarriageReturn = false;
+            _writer.Write('\r');
+        }
+        _writer.Flush();
+    }
+
+    public void Dispose() => ArrayPool<char>.Shared.Return(_chars);
+
+    // A '\r' at the end of the chunk is held back, as the '\n' that makes it a line ending may come with the next one.
+    private void WriteWithNormalizedLineEndings(ReadOnlySpan<char> chars)
+    {
+        if (_pendingCarriageReturn && !chars.IsEmpty)
+        {
+            _pendingCarriageReturn = false;
+            if (chars[0] == '\n')
+            {
+                _writer.WriteLine();
+                chars = chars.Slice(1);
+            }
+            else
+            {
+                _writer.Write('\r');
+            }
+        }
+
+        int lineEnd;
+        while ((lineEnd = chars.IndexOf('\n')) >= 0)
+        {
+            ReadOnlySpan<char> line = chars.Slice(0, lineEnd);
+            _writer.WriteLine(line.Length > 0 && line[^1] == '\r' ? line.Slice(0, line.Length - 1) : line);
+            chars = chars.Slice(lineEnd + 1);
+        }
+
+        if (chars.Length > 0 && chars[^1] == '\r')
+        {
+            _pendingCarriageReturn = true;
+            chars = chars.Slice(0, chars.Length - 1);
+        }
+        _writer.Write(chars);
+    }
+}
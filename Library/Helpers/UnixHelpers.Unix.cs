@@ -24,10 +24,10 @@ internal static partial class UnixHelpers
         return access(path, 1) == 0;
     }
 
-    internal static string[] GetEnvironmentVariables(ProcessStartOptions options)
+    internal static string[] GetEnvironmentVariables(IDictionary<string, string?> environment)
     {
         List<string> envList = new();
-        foreach (var kvp in options.Environment)
+        foreach (var kvp in environment)
         {
             if (kvp.Value != null)
             {
@@ -100,18 +100,19 @@ internal static partial class UnixHelpers
     /// Read all available data from the file descriptor until EAGAIN/EWOULDBLOCK
     /// </summary>
     /// <returns>True if more data may be available, false if EOF (pipe closed)</returns>
-    internal static bool DrainPipe(SafeFileHandle pipeHandle, ref byte[] buffer, ref int bytesRead)
+    internal static bool DrainPipe(SafeFileHandle pipeHandle, ref byte[] buffer, ref int bytesRead, int maxReadSize = int.MaxValue)
     {
         int EWOULDBLOCK = OperatingSystem.IsLinux() ? 11 : 35;
 
         nint result;
         while (true)
         {
+            int requested = Math.Min(maxReadSize, buffer.Length - bytesRead);
             unsafe
             {
                 fixed (byte* ptr = &buffer[bytesRead])
                 {
-                    result = read(pipeHandle, ptr, buffer.Length - bytesRead);
+                    result = read(pipeHandle, ptr, requested);
                 }
             }
 
@@ -123,6 +124,11 @@ internal static partial class UnixHelpers
                 {
                     BufferHelper.RentLargerBuffer(ref buffer);
                 }
+                else if (result == requested)
+                {
+                    // The read was limited by maxReadSize, more data may be available.
+                    continue;
+                }
                 else
                 {
                     // Read has returned less data than requested, so we have drained the pipe for now.
@@ -0,0 +1,54 @@
+using System.Collections.Generic;
+
+namespace System.TBA;
+
+/// <summary>
+/// Describes the outcome of a best-effort termination of a process tree.
+/// </summary>
+// Design: tree kill is inherently racy (new children can be spawned, others can exit on their own),
+// so instead of a single bool we report what was found and what survived.
+public sealed class KillTreeResult
+{
+    // Design: ctor is public to allow for mocking in tests.
+    public KillTreeResult(IReadOnlyList<int> processIds, IReadOnlyList<int> survivingProcessIds)
+    {
+        ArgumentNullException.ThrowIfNull(processIds);
+        ArgumentNullException.ThrowIfNull(survivingProcessIds);
+
+        ProcessIds = processIds;
+        SurvivingProcessIds = survivingProcessIds;
+    }
+
+    /// <summary>
+    /// Gets the IDs of all the processes that were found in the tree, including the root process.
+    /// </summary>
+    public IReadOnlyList<int> ProcessIds { get; }
+
+    /// <summary>
+    /// Gets the IDs of the processes that were still running when the settle timeout elapsed.
+    /// </summary>
+    public IReadOnlyList<int> SurvivingProcessIds { get; }
+
+    /// <summary>
+    /// Gets a value indicating whether all the processes that were found have been terminated.
+    /// </summary>
+    public bool AllTerminated => SurvivingProcessIds.Count == 0;
+
+    /// <summary>
+    /// Gets a value indicating whether the specified process was found in the tree and has been terminated.
+    /// </summary>
+    public bool WasTerminated(int processId) => Contains(ProcessIds, processId) && !Contains(SurvivingProcessIds, processId);
+
+    private static bool Contains(IReadOnlyList<int> processIds, int processId)
+    {
+        for (int i = 0; i < processIds.Count; i++)
+        {
+            if (processIds[i] == processId)
+            {
+                return true;
+            }
+        }
+
+        return false;
+    }
+}
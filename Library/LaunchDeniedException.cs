@@ -0,0 +1,24 @@
+namespace System.TBA;
+
+/// <summary>
+/// The exception that is thrown when <see cref="ProcessStartOptions.LaunchAuditCallback"/> denies the start of a process.
+/// </summary>
+/// <remarks>
+/// When the callback denied the launch by throwing, the exception it has thrown is available as <see cref="Exception.InnerException"/>.
+/// </remarks>
+public sealed class LaunchDeniedException : Exception
+{
+    // Design: ctor is public to allow for mocking in tests.
+    public LaunchDeniedException(LaunchInfo launchInfo, Exception? innerException = null)
+        : base($"The launch of '{launchInfo?.ResolvedPath}' was denied by the launch audit callback.", innerException)
+    {
+        ArgumentNullException.ThrowIfNull(launchInfo);
+
+        LaunchInfo = launchInfo;
+    }
+
+    /// <summary>
+    /// Gets the description of the process whose start was denied.
+    /// </summary>
+    public LaunchInfo LaunchInfo { get; }
+}
@@ -0,0 +1,39 @@
+using System.Collections.Generic;
+
+namespace System.TBA;
+
+/// <summary>
+/// Describes a process that is about to be started, as seen by <see cref="ProcessStartOptions.LaunchAuditCallback"/>.
+/// </summary>
+public sealed class LaunchInfo
+{
+    // Design: ctor is public to allow for mocking in tests.
+    public LaunchInfo(string resolvedPath, IReadOnlyList<string> arguments, string? workingDirectory)
+    {
+        ArgumentException.ThrowIfNullOrEmpty(resolvedPath);
+        ArgumentNullException.ThrowIfNull(arguments);
+
+        ResolvedPath = resolvedPath;
+        Arguments = arguments;
+        WorkingDirectory = workingDirectory;
+    }
+
+    /// <summary>
+    /// Gets the absolute path of the executable that is going to be started.
+    /// </summary>
+    /// <remarks>
+    /// It's the result of the path resolution (see <see cref="ProcessStartOptions.ResolvePath"/>), never the bare file name,
+    /// so it can't be spoofed by placing a different executable with the same name earlier in PATH.
+    /// </remarks>
+    public string ResolvedPath { get; }
+
+    /// <summary>
+    /// Gets the command-line arguments that are going to be passed to the executable.
+    /// </summary>
+    public IReadOnlyList<string> Arguments { get; }
+
+    /// <summary>
+    /// Gets the working directory of the process, or null when it's going to inherit the current directory.
+    /// </summary>
+    public string? WorkingDirectory { get; }
+}
@@ -10,8 +10,9 @@ namespace System.TBA;
 
 internal static class Multiplexing
 {
-    internal static void ReadProcessOutputCore(SafeChildProcessHandle processHandle, SafeFileHandle readStdOut, SafeFileHandle readStdErr, TimeoutHelper timeout,
-        ref int outputBytesRead, ref int errorBytesRead, ref byte[] outputBuffer, ref byte[] errorBuffer)
+    internal static bool ReadProcessOutputCore(SafeChildProcessHandle processHandle, SafeFileHandle readStdOut, SafeFileHandle readStdErr, TimeoutHelper timeout, int maxReadSize,
+        ref int outputBytesRead, ref int errorBytesRead, ref byte[] outputBuffer, ref byte[] errorBuffer,
+        SpanConsumer? outputConsumer = null, SpanConsumer? errorConsumer = null)
     {
         int outputFd = (int)readStdOut.DangerousGetHandle();
         int errorFd = (int)readStdErr.DangerousGetHandle();
@@ -32,14 +33,25 @@ internal static class Multiplexing
             while (!processExited && (!outputClosed || !errorClosed))
             {
                 Span<KEvent> events = stackalloc KEvent[3];
-                if (!timeout.TryGetRemainingMilliseconds(out int timeoutMs))
-                {
-                    return;
-                }
-                int numEvents = WaitForEvents(kq, events, timeoutMs);
-                if (numEvents == 0)
+                int numEvents;
+                if (!timeout.TryGetRemainingMilliseconds(out int timeoutMs) || (numEvents = WaitForEvents(kq, events, timeoutMs)) == 0)
                 {
-                    return; // Timeout
+                    // Timeout: kill the process and keep what it has written up to that point.
+                    processHandle.KillCore(throwOnError: false);
+
+                    if (!outputClosed)
+                    {
+                        UnixHelpers.DrainPipe(readStdOut, ref outputBuffer, ref outputBytesRead, maxReadSize);
+                        BufferHelper.FlushToConsumer(outputConsumer, outputBuffer, ref outputBytesRead);
+                    }
+
+                    if (!errorClosed)
+                    {
+                        UnixHelpers.DrainPipe(readStdErr, ref errorBuffer, ref errorBytesRead, maxReadSize);
+                        BufferHelper.FlushToConsumer(errorConsumer, errorBuffer, ref errorBytesRead);
+                    }
+
+                    return true;
                 }
 
                 for (int i = 0; i < numEvents; i++)
@@ -52,11 +64,13 @@ internal static class Multiplexing
                         
                         if (fd == outputFd && !outputClosed)
                         {
-                            outputClosed = !UnixHelpers.DrainPipe(readStdOut, ref outputBuffer, ref outputBytesRead);
+                            outputClosed = !UnixHelpers.DrainPipe(readStdOut, ref outputBuffer, ref outputBytesRead, maxReadSize);
+                            BufferHelper.FlushToConsumer(outputConsumer, outputBuffer, ref outputBytesRead);
                         }
                         else if (fd == errorFd && !errorClosed)
                         {
-                            errorClosed = !UnixHelpers.DrainPipe(readStdErr, ref errorBuffer, ref errorBytesRead);
+                            errorClosed = !UnixHelpers.DrainPipe(readStdErr, ref errorBuffer, ref errorBytesRead, maxReadSize);
+                            BufferHelper.FlushToConsumer(errorConsumer, errorBuffer, ref errorBytesRead);
                         }
                     }
                     else if (evt.filter == EVFILT_PROC && (evt.fflags & NOTE_EXIT) != 0)
@@ -77,14 +91,18 @@ internal static class Multiplexing
 
                 if (!outputClosed)
                 {
-                    UnixHelpers.DrainPipe(readStdOut, ref outputBuffer, ref outputBytesRead);
+                    UnixHelpers.DrainPipe(readStdOut, ref outputBuffer, ref outputBytesRead, maxReadSize);
+                    BufferHelper.FlushToConsumer(outputConsumer, outputBuffer, ref outputBytesRead);
                 }
 
                 if (!errorClosed)
                 {
-                    UnixHelpers.DrainPipe(readStdErr, ref errorBuffer, ref errorBytesRead);
+                    UnixHelpers.DrainPipe(readStdErr, ref errorBuffer, ref errorBytesRead, maxReadSize);
+                    BufferHelper.FlushToConsumer(errorConsumer, errorBuffer, ref errorBytesRead);
                 }
             }
+
+            return false;
         }
         finally
         {
@@ -93,7 +111,8 @@ internal static class Multiplexing
         }
     }
 
-    internal static unsafe void ReadCombinedOutputCore(SafeFileHandle fileHandle, SafeChildProcessHandle processHandle, TimeoutHelper timeout, ref int totalBytesRead, ref byte[] array)
+    internal static unsafe bool ReadCombinedOutputCore(SafeFileHandle fileHandle, SafeChildProcessHandle processHandle, TimeoutHelper timeout, ref int totalBytesRead, ref byte[] array,
+        SpanConsumer? consumer = null)
     {
         int kq = create_kqueue_cloexec();
         if (kq == -1)
@@ -113,7 +132,11 @@ internal static class Multiplexing
                 int numEvents;
                 if (!timeout.TryGetRemainingMilliseconds(out int timeoutMs) || (numEvents = WaitForEvents(kq, events, timeoutMs)) == 0)
                 {
-                    return; // Timeout
+                    // Timeout: kill the process and keep what it has written up to that point.
+                    processHandle.KillCore(throwOnError: false);
+                    UnixHelpers.DrainPipe(fileHandle, ref array, ref totalBytesRead);
+                    BufferHelper.FlushToConsumer(consumer, array, ref totalBytesRead);
+                    return true;
                 }
 
                 for (int i = 0; i < numEvents; i++)
@@ -123,6 +146,7 @@ internal static class Multiplexing
                     if (evt.filter == EVFILT_READ)
                     {
                         closed = !UnixHelpers.DrainPipe(fileHandle, ref array, ref totalBytesRead);
+                        BufferHelper.FlushToConsumer(consumer, array, ref totalBytesRead);
                     }
                     else if (evt.filter == EVFILT_PROC && (evt.fflags & NOTE_EXIT) != 0)
                     {
@@ -140,7 +164,10 @@ internal static class Multiplexing
                 // - Waiting on kqueue with zero timeout: doesn't work, kqueue doesn't always signal again.
                 Thread.Sleep(TimeSpan.FromMilliseconds(1));
                 UnixHelpers.DrainPipe(fileHandle, ref array, ref totalBytesRead);
+                BufferHelper.FlushToConsumer(consumer, array, ref totalBytesRead);
             }
+
+            return false;
         }
         finally
         {
@@ -11,8 +11,9 @@ namespace System.TBA;
 
 internal static class Multiplexing
 {
-    internal static void ReadProcessOutputCore(SafeChildProcessHandle processHandle, SafeFileHandle readStdOut, SafeFileHandle readStdErr, TimeoutHelper timeout,
-        ref int outputBytesRead, ref int errorBytesRead, ref byte[] outputBuffer, ref byte[] errorBuffer)
+    internal static bool ReadProcessOutputCore(SafeChildProcessHandle processHandle, SafeFileHandle readStdOut, SafeFileHandle readStdErr, TimeoutHelper timeout, int maxReadSize,
+        ref int outputBytesRead, ref int errorBytesRead, ref byte[] outputBuffer, ref byte[] errorBuffer,
+        SpanConsumer? outputConsumer = null, SpanConsumer? errorConsumer = null)
     {
         using FileStream stdoutStream = new(readStdOut, FileAccess.Read, bufferSize: 1, isAsync: false);
         using FileStream stderrStream = new(readStdErr, FileAccess.Read, bufferSize: 1, isAsync: false);
@@ -84,7 +85,22 @@ internal static class Multiplexing
             }
             else if (pollResult == 0)
             {
-                return; // Timeout occurred
+                // Timeout occurred: kill the process and keep what it has written up to that point.
+                processHandle.KillCore(throwOnError: false);
+
+                if (!outputClosed)
+                {
+                    UnixHelpers.DrainPipe(readStdOut, ref outputBuffer, ref outputBytesRead, maxReadSize);
+                    BufferHelper.FlushToConsumer(outputConsumer, outputBuffer, ref outputBytesRead);
+                }
+
+                if (!errorClosed)
+                {
+                    UnixHelpers.DrainPipe(readStdErr, ref errorBuffer, ref errorBytesRead, maxReadSize);
+                    BufferHelper.FlushToConsumer(errorConsumer, errorBuffer, ref errorBytesRead);
+                }
+
+                return true;
             }
 
             // Check which file descriptors have data available
@@ -98,21 +114,25 @@ internal static class Multiplexing
                 if (hasPidFd && i == numFds - 1)
                 {
                     // Process is the last descriptor if pidfd is used.
-                    // Since we have already checked both stdout and stderr,
-                    // we just close any remaining open streams and exit.
+                    // We have already read one chunk from both stdout and stderr,
+                    // so we drain whatever is left in the pipes, close any remaining open streams and exit.
                     if (!outputClosed)
                     {
+                        UnixHelpers.DrainPipe(readStdOut, ref outputBuffer, ref outputBytesRead, maxReadSize);
+                        BufferHelper.FlushToConsumer(outputConsumer, outputBuffer, ref outputBytesRead);
                         stdoutStream.Close();
                         outputClosed = true;
                     }
 
                     if (!errorClosed)
                     {
+                        UnixHelpers.DrainPipe(readStdErr, ref errorBuffer, ref errorBytesRead, maxReadSize);
+                        BufferHelper.FlushToConsumer(errorConsumer, errorBuffer, ref errorBytesRead);
                         stderrStream.Close();
                         errorClosed = true;
                     }
 
-                    return;
+                    return false;
                 }
 
                 bool isError = pollFdsBuffer[i].fd == errorFd;
@@ -121,10 +141,11 @@ internal static class Multiplexing
                 ref int currentBytesRead = ref (isError ? ref errorBytesRead : ref outputBytesRead);
                 ref bool closed = ref (isError ? ref errorClosed : ref outputClosed);
 
-                int bytesRead = currentFs.Read(currentArray.AsSpan(currentBytesRead));
+                int bytesRead = currentFs.Read(currentArray.AsSpan(currentBytesRead, Math.Min(maxReadSize, currentArray.Length - currentBytesRead)));
                 if (bytesRead > 0)
                 {
                     currentBytesRead += bytesRead;
+                    BufferHelper.FlushToConsumer(isError ? errorConsumer : outputConsumer, currentArray, ref currentBytesRead);
 
                     if (currentBytesRead == currentArray.Length)
                     {
@@ -138,9 +159,12 @@ internal static class Multiplexing
                 }
             }
         }
+
+        return false;
     }
 
-    internal static unsafe void ReadCombinedOutputCore(SafeFileHandle fileHandle, SafeChildProcessHandle processHandle, TimeoutHelper timeout, ref int totalBytesRead, ref byte[] array)
+    internal static unsafe bool ReadCombinedOutputCore(SafeFileHandle fileHandle, SafeChildProcessHandle processHandle, TimeoutHelper timeout, ref int totalBytesRead, ref byte[] array,
+        SpanConsumer? consumer = null)
     {
         // Get the pidfd for process exit detection
         int pidfd = (int)processHandle.DangerousGetHandle();
@@ -191,7 +215,11 @@ internal static class Multiplexing
             }
             else if (pollResult == 0)
             {
-                return; // Timeout occurred
+                // Timeout occurred: kill the process and keep what it has written up to that point.
+                processHandle.KillCore(throwOnError: false);
+                UnixHelpers.DrainPipe(fileHandle, ref array, ref totalBytesRead);
+                BufferHelper.FlushToConsumer(consumer, array, ref totalBytesRead);
+                return true;
             }
 
             // Check which file descriptors have data available
@@ -205,12 +233,14 @@ internal static class Multiplexing
                 if (hasPidFd && i == 1)
                 {
                     // Process has exited (pidfd is always the last descriptor)
-                    return;
+                    return false;
                 }
 
-                if (!UnixHelpers.DrainPipe(fileHandle, ref array, ref totalBytesRead))
+                bool moreDataAvailable = UnixHelpers.DrainPipe(fileHandle, ref array, ref totalBytesRead);
+                BufferHelper.FlushToConsumer(consumer, array, ref totalBytesRead);
+                if (!moreDataAvailable)
                 {
-                    return; // EOF reached
+                    return false; // EOF reached
                 }
             }
         }
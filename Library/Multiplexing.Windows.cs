@@ -6,8 +6,9 @@ namespace System.TBA;
 
 internal static class Multiplexing
 {
-    internal static void ReadProcessOutputCore(SafeChildProcessHandle processHandle, SafeFileHandle readStdOut, SafeFileHandle readStdErr, TimeoutHelper timeout,
-        ref int outputBytesRead, ref int errorBytesRead, ref byte[] outputBuffer, ref byte[] errorBuffer)
+    internal static bool ReadProcessOutputCore(SafeChildProcessHandle processHandle, SafeFileHandle readStdOut, SafeFileHandle readStdErr, TimeoutHelper timeout, int maxReadSize,
+        ref int outputBytesRead, ref int errorBytesRead, ref byte[] outputBuffer, ref byte[] errorBuffer,
+        SpanConsumer? outputConsumer = null, SpanConsumer? errorConsumer = null)
     {
         MemoryHandle outputPin = outputBuffer.AsMemory().Pin();
         MemoryHandle errorPin = errorBuffer.AsMemory().Pin();
@@ -29,84 +30,121 @@ internal static class Multiplexing
             unsafe
             {
                 // Issue first reads.
-                Interop.Kernel32.ReadFile(readStdOut, (byte*)outputPin.Pointer, outputBuffer.Length, IntPtr.Zero, outputContext.GetOverlapped());
-                Interop.Kernel32.ReadFile(readStdErr, (byte*)errorPin.Pointer, errorBuffer.Length, IntPtr.Zero, errorContext.GetOverlapped());
+                Interop.Kernel32.ReadFile(readStdOut, (byte*)outputPin.Pointer, Math.Min(maxReadSize, outputBuffer.Length), IntPtr.Zero, outputContext.GetOverlapped());
+                Interop.Kernel32.ReadFile(readStdErr, (byte*)errorPin.Pointer, Math.Min(maxReadSize, errorBuffer.Length), IntPtr.Zero, errorContext.GetOverlapped());
             }
 
-            while (!readStdOut.IsClosed || !readStdErr.IsClosed)
+            try
             {
-                int waitResult = timeout.TryGetRemainingMilliseconds(out int remainingMilliseconds)
-                    ? WaitHandle.WaitAny(waitHandles, remainingMilliseconds)
-                    : WaitHandle.WaitTimeout;
-
-                if (waitResult is 1 or 2)
+                while (!readStdOut.IsClosed || !readStdErr.IsClosed)
                 {
-                    bool isError = waitResult == 2;
-
-                    OverlappedContext currentContext = isError ? errorContext : outputContext;
-                    SafeFileHandle currentFileHandle = isError ? readStdErr : readStdOut;
-                    ref int totalBytesRead = ref (isError ? ref errorBytesRead : ref outputBytesRead);
-                    ref byte[] currentBuffer = ref (isError ? ref errorBuffer : ref outputBuffer);
+                    int waitResult = timeout.TryGetRemainingMilliseconds(out int remainingMilliseconds)
+                        ? WaitHandle.WaitAny(waitHandles, remainingMilliseconds)
+                        : WaitHandle.WaitTimeout;
 
-                    int bytesRead = currentContext.GetOverlappedResult(currentFileHandle);
-                    if (bytesRead > 0)
+                    if (waitResult is 1 or 2)
                     {
-                        totalBytesRead += bytesRead;
+                        bool isError = waitResult == 2;
 
-                        if (totalBytesRead == currentBuffer.Length)
+                        OverlappedContext currentContext = isError ? errorContext : outputContext;
+                        SafeFileHandle currentFileHandle = isError ? readStdErr : readStdOut;
+                        ref int totalBytesRead = ref (isError ? ref errorBytesRead : ref outputBytesRead);
+                        ref byte[] currentBuffer = ref (isError ? ref errorBuffer : ref outputBuffer);
+
+                        int bytesRead = currentContext.GetOverlappedResult(currentFileHandle);
+                        if (bytesRead > 0)
                         {
-                            ref MemoryHandle currentPin = ref (isError ? ref errorPin : ref outputPin);
-                            currentPin.Dispose();
+                            totalBytesRead += bytesRead;
 
-                            BufferHelper.RentLargerBuffer(ref currentBuffer);
+                            // No read is pending on this handle at this point, so the consumer can't observe the buffer being written to.
+                            // If it throws, the read pending on the other handle is canceled below.
+                            BufferHelper.FlushToConsumer(isError ? errorConsumer : outputConsumer, currentBuffer, ref totalBytesRead);
 
-                            currentPin = currentBuffer.AsMemory().Pin();
-                        }
+                            if (totalBytesRead == currentBuffer.Length)
+                            {
+                                ref MemoryHandle currentPin = ref (isError ? ref errorPin : ref outputPin);
+                                currentPin.Dispose();
 
-                        unsafe
-                        {
-                            void* pinPointer = isError ? errorPin.Pointer : outputPin.Pointer;
-                            int sliceLength = currentBuffer.Length - totalBytesRead;
-                            byte* targetPointer = (byte*)pinPointer + totalBytesRead;
+                                BufferHelper.RentLargerBuffer(ref currentBuffer);
+
+                                currentPin = currentBuffer.AsMemory().Pin();
+                            }
+
+                            unsafe
+                            {
+                                void* pinPointer = isError ? errorPin.Pointer : outputPin.Pointer;
+                                int sliceLength = Math.Min(maxReadSize, currentBuffer.Length - totalBytesRead);
+                                byte* targetPointer = (byte*)pinPointer + totalBytesRead;
 
-                            Interop.Kernel32.ReadFile(currentFileHandle, targetPointer, sliceLength, IntPtr.Zero, currentContext.GetOverlapped());
+                                Interop.Kernel32.ReadFile(currentFileHandle, targetPointer, sliceLength, IntPtr.Zero, currentContext.GetOverlapped());
+                            }
+                        }
+                        else
+                        {
+                            if (!currentFileHandle.IsClosed)
+                            {
+                                // Close the handle to stop further reads.
+                                currentFileHandle.Close();
+                                // And reset the wait handle to avoid triggering on closed handle.
+                                currentContext.WaitHandle.Reset();
+                            }
                         }
                     }
-                    else
+                    else if (waitResult == 0 || waitResult == WaitHandle.WaitTimeout)
                     {
-                        if (!currentFileHandle.IsClosed)
+                        // Either the process has exited, or we have timed out.
+                        // In both cases, we stop reading, but keep what the pending reads have already received
+                        // and what is still buffered in the pipes.
+                        if (waitResult == WaitHandle.WaitTimeout)
                         {
-                            // Close the handle to stop further reads.
-                            currentFileHandle.Close();
-                            // And reset the wait handle to avoid triggering on closed handle.
-                            currentContext.WaitHandle.Reset();
+                            processHandle.KillCore(throwOnError: false);
                         }
-                    }
-                }
-                else if (waitResult == 0 || waitResult == WaitHandle.WaitTimeout)
-                {
-                    // Either the process has exited, or we have timed out.
-                    // In both cases, we stop reading.
 
-                    if (!readStdOut.IsClosed)
-                    {
-                        outputContext.CancelPendingIO(readStdOut);
-                    }
+                        // No reads are pending after the cancellation, so the buffers don't need to stay pinned
+                        // when the drain has to rent larger ones.
+                        if (!readStdOut.IsClosed)
+                        {
+                            outputBytesRead += outputContext.CancelPendingIO(readStdOut);
+                            outputPin.Dispose();
+                            outputContext.DrainPipe(readStdOut, ref outputBuffer, ref outputBytesRead, maxReadSize);
+                            BufferHelper.FlushToConsumer(outputConsumer, outputBuffer, ref outputBytesRead);
+                            readStdOut.Close();
+                        }
 
-                    if (!readStdErr.IsClosed)
-                    {
-                        errorContext.CancelPendingIO(readStdErr);
-                    }
+                        if (!readStdErr.IsClosed)
+                        {
+                            errorBytesRead += errorContext.CancelPendingIO(readStdErr);
+                            errorPin.Dispose();
+                            errorContext.DrainPipe(readStdErr, ref errorBuffer, ref errorBytesRead, maxReadSize);
+                            BufferHelper.FlushToConsumer(errorConsumer, errorBuffer, ref errorBytesRead);
+                            readStdErr.Close();
+                        }
 
-                    if (waitResult == WaitHandle.WaitTimeout)
+                        if (waitResult == WaitHandle.WaitTimeout)
+                        {
+                            return true;
+                        }
+                    }
+                    else
                     {
-                        return;
+                        throw new InvalidOperationException($"Unexpected wait result: {waitResult}.");
                     }
                 }
-                else
+            }
+            catch
+            {
+                // E.g. a consumer has thrown. The reads still pending must complete before the OVERLAPPED structures are freed.
+                if (!readStdOut.IsClosed)
+                {
+                    outputContext.CancelPendingIO(readStdOut);
+                }
+
+                if (!readStdErr.IsClosed)
                 {
-                    throw new InvalidOperationException($"Unexpected wait result: {waitResult}.");
+                    errorContext.CancelPendingIO(readStdErr);
                 }
+
+                throw;
             }
         }
         finally
@@ -114,15 +152,19 @@ internal static class Multiplexing
             outputPin.Dispose();
             errorPin.Dispose();
         }
+
+        return false;
     }
 
-    internal static unsafe void ReadCombinedOutputCore(SafeFileHandle fileHandle, SafeChildProcessHandle processHandle, TimeoutHelper timeout, ref int totalBytesRead, ref byte[] array)
+    internal static unsafe bool ReadCombinedOutputCore(SafeFileHandle fileHandle, SafeChildProcessHandle processHandle, TimeoutHelper timeout, ref int totalBytesRead, ref byte[] array,
+        SpanConsumer? consumer = null)
     {
         using OverlappedContext overlappedContext = OverlappedContext.Allocate();
         using Interop.Kernel32.ProcessWaitHandle processWaitHandle = new(processHandle);
 
         WaitHandle[] waitHandles = [processWaitHandle, overlappedContext.WaitHandle];
 
+        bool timedOut;
         while (true)
         {
             Span<byte> remainingBytes = array.AsSpan(totalBytesRead);
@@ -140,7 +182,14 @@ internal static class Multiplexing
                     if (waitResult == 0 || waitResult == WaitHandle.WaitTimeout)
                     {
                         // Process has exited or the read has timed out, stop reading (grandchild may still have pipe open)
-                        overlappedContext.CancelPendingIO(fileHandle);
+                        // but keep what the pending read has already received.
+                        timedOut = waitResult == WaitHandle.WaitTimeout;
+                        if (timedOut)
+                        {
+                            processHandle.KillCore(throwOnError: false);
+                        }
+
+                        totalBytesRead += overlappedContext.CancelPendingIO(fileHandle);
                         break;
                     }
                 }
@@ -148,10 +197,13 @@ internal static class Multiplexing
                 int bytesRead = overlappedContext.GetOverlappedResult(fileHandle);
                 if (bytesRead <= 0)
                 {
-                    break;
+                    return false;
                 }
 
                 totalBytesRead += bytesRead;
+
+                // No read is pending at this point, so the consumer can't observe the buffer being written to.
+                BufferHelper.FlushToConsumer(consumer, array, ref totalBytesRead);
             }
 
             if (array.Length == totalBytesRead)
@@ -159,5 +211,11 @@ internal static class Multiplexing
                 BufferHelper.RentLargerBuffer(ref array);
             }
         }
+
+        // Keep what is still buffered in the pipe too.
+        overlappedContext.DrainPipe(fileHandle, ref array, ref totalBytesRead);
+        BufferHelper.FlushToConsumer(consumer, array, ref totalBytesRead);
+        fileHandle.Close();
+        return timedOut;
     }
 }
@@ -0,0 +1,96 @@
+namespace System.TBA;
+
+/// <summary>
+/// The exception that is thrown when a process has exited with a non-zero exit code or has been terminated by a signal.
+/// </summary>
+/// <remarks>
+/// It carries all the information that is typically needed to diagnose the failure of a wrapped command-line tool.
+/// </remarks>
+public sealed class ProcessExitException : Exception
+{
+    // Design: ctor is public to allow for mocking in tests.
+    public ProcessExitException(ProcessExitStatus exitStatus, int processId, string standardErrorTail, string? commandLine, string? workingDirectory, TimeSpan elapsed)
+        : this(exitStatus, processId, standardErrorTail, commandLine, workingDirectory, elapsed, exitCodeDescription: null)
+    {
+    }
+
+    public ProcessExitException(ProcessExitStatus exitStatus, int processId, string standardErrorTail, string? commandLine, string? workingDirectory, TimeSpan elapsed, string? exitCodeDescription)
+        : base(CreateMessage(exitStatus, processId, standardErrorTail, commandLine, elapsed, exitCodeDescription))
+    {
+        ArgumentNullException.ThrowIfNull(exitStatus);
+        ArgumentNullException.ThrowIfNull(standardErrorTail);
+
+        ExitStatus = exitStatus;
+        ProcessId = processId;
+        StandardErrorTail = standardErrorTail;
+        CommandLine = commandLine;
+        WorkingDirectory = workingDirectory;
+        Elapsed = elapsed;
+        ExitCodeDescription = exitCodeDescription;
+    }
+
+    /// <summary>
+    /// Gets the exit status of the process.
+    /// </summary>
+    public ProcessExitStatus ExitStatus { get; }
+
+    /// <summary>
+    /// Gets the exit code of the process.
+    /// </summary>
+    public int ExitCode => ExitStatus.ExitCode;
+
+    /// <summary>
+    /// Gets the POSIX signal that terminated the process, or null if the process exited normally.
+    /// </summary>
+    public PosixSignal? Signal => ExitStatus.Signal;
+
+    /// <summary>
+    /// Gets the process ID that was used when it was running.
+    /// </summary>
+    public int ProcessId { get; }
+
+    /// <summary>
+    /// Gets the last part of the standard error of the process, which usually contains the reason of the failure.
+    /// </summary>
+    public string StandardErrorTail { get; }
+
+    /// <summary>
+    /// Gets the command line of the process: the file name followed by the arguments, or null if not known.
+    /// </summary>
+    /// <remarks>It's meant for diagnostics only, the quoting is not guaranteed to match the rules of any particular shell.</remarks>
+    public string? CommandLine { get; }
+
+    /// <summary>
+    /// Gets the working directory of the process, or null if not known.
+    /// </summary>
+    public string? WorkingDirectory { get; }
+
+    /// <summary>
+    /// Gets the time that elapsed from starting the process to observing its exit.
+    /// </summary>
+    public TimeSpan Elapsed { get; }
+
+    /// <summary>
+    /// Gets the human-readable meaning of the exit code, or null if not known.
+    /// </summary>
+    /// <remarks>It comes from <see cref="ProcessStartOptions.ExitCodeDescriptions"/>.</remarks>
+    public string? ExitCodeDescription { get; }
+
+    private static string CreateMessage(ProcessExitStatus exitStatus, int processId, string standardErrorTail, string? commandLine, TimeSpan elapsed, string? exitCodeDescription)
+    {
+        ArgumentNullException.ThrowIfNull(exitStatus);
+
+        string reason = exitStatus.Canceled
+            ? "was killed on timeout or cancellation"
+            : exitStatus.Signal is { } signal
+            ? $"was terminated by {signal}"
+            : string.IsNullOrEmpty(exitCodeDescription)
+                ? $"exited with code {exitStatus.ExitCode}"
+                : $"exited with code {exitStatus.ExitCode} ({exitCodeDescription})";
+        string message = $"Process '{commandLine}' (PID {processId}) {reason} after {elapsed.TotalMilliseconds:F0}ms.";
+
+        return string.IsNullOrEmpty(standardErrorTail)
+            ? message
+            : $"{message} Standard error:{Environment.NewLine}{standardErrorTail}";
+    }
+}
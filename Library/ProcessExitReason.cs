@@ -0,0 +1,16 @@
+namespace System.TBA;
+
+/// <summary>
+/// Specifies why a process has exited.
+/// </summary>
+public enum ProcessExitReason
+{
+    /// <summary>The process exited on its own, e.g. by returning from main() or calling exit().</summary>
+    Exited,
+
+    /// <summary>The process was terminated by a signal (Unix only), for example it crashed or was killed by someone else.</summary>
+    Signaled,
+
+    /// <summary>The process was killed by the library on timeout or cancellation.</summary>
+    Canceled,
+}
@@ -11,6 +11,9 @@ public sealed class ProcessExitStatus
         ExitCode = exitCode;
         Signal = signal;
         Canceled = cancelled;
+        Reason = cancelled ? ProcessExitReason.Canceled
+            : signal is not null ? ProcessExitReason.Signaled
+            : ProcessExitReason.Exited;
     }
 
     /// <summary>
@@ -38,4 +41,19 @@ public sealed class ProcessExitStatus
     /// Gets a value indicating whether the process has been terminated due to timeout or cancellation.
     /// </summary>
     public bool Canceled { get; }
+
+    /// <summary>
+    /// Gets the reason why the process has exited.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// It's derived from the same wait result as <see cref="ExitCode"/> and <see cref="Signal"/>,
+    /// so the three are always consistent with each other and no second call that could observe a different state is needed.
+    /// </para>
+    /// <para>
+    /// On Windows, a crash (e.g. an access violation) is reported as <see cref="ProcessExitReason.Exited"/>
+    /// with an NTSTATUS exit code like 0xC0000005, as the OS does not distinguish it from a regular exit.
+    /// </para>
+    /// </remarks>
+    public ProcessExitReason Reason { get; }
 }
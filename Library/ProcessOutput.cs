@@ -1,7 +1,18 @@
-﻿namespace System.TBA;
+﻿using System.Collections.Generic;
+using System.Text;
+
+namespace System.TBA;
 
 public readonly struct ProcessOutput
 {
+    // Long enough for a typical error message with a short stack trace.
+    private const int MaxStandardErrorTailLength = 4096;
+
+    private readonly string? _commandLine;
+    private readonly string? _workingDirectory;
+    private readonly TimeSpan _elapsed;
+    private readonly string? _exitCodeDescription;
+
     /// <summary>
     /// Gets the exit status of the process after it has terminated.
     /// </summary>
@@ -30,4 +41,57 @@ public readonly struct ProcessOutput
         StandardError = standardError;
         ProcessId = processId;
     }
+
+    internal ProcessOutput(ProcessExitStatus exitStatus, string standardOutput, string standardError, int processId, ProcessStartOptions options, TimeSpan elapsed)
+        : this(exitStatus, standardOutput, standardError, processId)
+    {
+        _commandLine = FormatCommandLine(options);
+        _workingDirectory = options.WorkingDirectory ?? Environment.CurrentDirectory;
+        _elapsed = elapsed;
+        // When the process was terminated by a signal, the exit code is made up and has no tool-specific meaning.
+        _exitCodeDescription = exitStatus.Signal is null ? options.ExitCodeDescriptions?.GetValueOrDefault(exitStatus.ExitCode) : null;
+    }
+
+    /// <summary>
+    /// Gets the exit code of the process, or throws an exception with rich diagnostics when the process has failed.
+    /// </summary>
+    /// <returns>The exit code of the process, which is always 0.</returns>
+    /// <exception cref="ProcessExitException">
+    /// Thrown when the process has exited with a non-zero exit code or has been terminated by a signal.
+    /// It carries the exit status, the last part of the standard error, the command line, the working directory and the elapsed time.
+    /// </exception>
+    /// <exception cref="InvalidOperationException">Thrown when the instance does not come from a process that has exited.</exception>
+    public int GetExitCodeOrThrowWithDiagnostics()
+    {
+        ProcessExitStatus exitStatus = ExitStatus ?? throw new InvalidOperationException("Process has not exited yet.");
+        if (exitStatus.ExitCode == 0 && exitStatus.Signal is null)
+        {
+            return 0;
+        }
+
+        string standardError = StandardError ?? string.Empty;
+        string standardErrorTail = standardError.Length > MaxStandardErrorTailLength
+            ? standardError.Substring(standardError.Length - MaxStandardErrorTailLength)
+            : standardError;
+
+        throw new ProcessExitException(exitStatus, ProcessId, standardErrorTail, _commandLine, _workingDirectory, _elapsed, _exitCodeDescription);
+    }
+
+    private static string FormatCommandLine(ProcessStartOptions options)
+    {
+        StringBuilder builder = new(options.FileName);
+        foreach (string argument in options.EffectiveArguments)
+        {
+            builder.Append(' ');
+            if (argument.Length == 0 || argument.AsSpan().IndexOfAny(' ', '\t', '"') >= 0)
+            {
+                builder.Append('"').Append(argument.Replace("\"", "\\\"")).Append('"');
+            }
+            else
+            {
+                builder.Append(argument);
+            }
+        }
+        return builder.ToString();
+    }
 }
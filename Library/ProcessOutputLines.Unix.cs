@@ -24,17 +24,19 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
         byte[] errorBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
         int outputStartIndex = 0, outputEndIndex = 0;
         int errorStartIndex = 0, errorEndIndex = 0;
+        Decoder? outputDecoder = _options.StandardStreamsUnbuffered ? encoding.GetDecoder() : null;
+        Decoder? errorDecoder = _options.StandardStreamsUnbuffered ? encoding.GetDecoder() : null;
 
         SafeFileHandle? parentOutputHandle = null, childOutputHandle = null, parentErrorHandle = null, childErrorHandle = null;
         try
         {
-            using SafeFileHandle inputHandle = Console.OpenStandardInputHandle();
+            using SafeFileHandle inputHandle = OpenStandardInputHandle();
             File.CreatePipe(out parentOutputHandle, out childOutputHandle);
             File.CreatePipe(out parentErrorHandle, out childErrorHandle);
 
             using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(_options, inputHandle, childOutputHandle, childErrorHandle);
 
-            _processId = processHandle.ProcessId;
+            OnStarted(processHandle.ProcessId);
 
             int outputFd = (int)parentOutputHandle.DangerousGetHandle();
             int errorFd = (int)parentErrorHandle.DangerousGetHandle();
@@ -105,7 +107,7 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                     byte[] currentBuffer = isError ? errorBuffer : outputBuffer;
 
                     // Read data from the file descriptor
-                    int availableSpace = currentBuffer.Length - currentEndIndex;
+                    int availableSpace = Math.Min(_options.StreamedOutputReadSize, currentBuffer.Length - currentEndIndex);
                     nint bytesRead;
                     unsafe
                     {
@@ -125,8 +127,20 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                         // Treat other errors as EOF
                         bytesRead = 0;
                     }
+                    else if (bytesRead > 0)
+                    {
+                        RecordBytesRead((int)bytesRead, isError);
+                    }
 
-                    if (bytesRead > 0)
+                    if (bytesRead > 0 && _options.StandardStreamsUnbuffered)
+                    {
+                        string chunk = DecodeUnbuffered(isError ? errorDecoder! : outputDecoder!, currentBuffer.AsSpan(currentEndIndex, (int)bytesRead), flush: false);
+                        if (chunk.Length > 0)
+                        {
+                            yield return new ProcessOutputLine(chunk, standardError: isError);
+                        }
+                    }
+                    else if (bytesRead > 0)
                     {
                         int remaining = (int)bytesRead + currentEndIndex - currentStartIndex;
                         int startIndex = currentStartIndex;
@@ -201,6 +215,14 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                                 encoding.GetString(currentBuffer, currentStartIndex, currentEndIndex - currentStartIndex),
                                 standardError: isError);
                         }
+                        else if (_options.StandardStreamsUnbuffered)
+                        {
+                            string chunk = DecodeUnbuffered(isError ? errorDecoder! : outputDecoder!, ReadOnlySpan<byte>.Empty, flush: true);
+                            if (chunk.Length > 0)
+                            {
+                                yield return new ProcessOutputLine(chunk, standardError: isError);
+                            }
+                        }
 
                         if (isError)
                         {
@@ -224,7 +246,7 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                     ? processHandle.WaitForExit()
                     : processHandle.WaitForExitOrKillOnTimeout(remaining);
             }
-            _exitStatus = exitStatus;
+            OnExited(exitStatus);
 
             yield break;
         }
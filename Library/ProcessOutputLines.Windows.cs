@@ -18,18 +18,21 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
         // NOTE: we could get current console Encoding here, it's omitted for the sake of simplicity of the proof of concept.
         Encoding encoding = _encoding ?? Encoding.UTF8;
         TimeoutHelper timeoutHelper = TimeoutHelper.Start(_timeout);
+        int readSize = _options.StreamedOutputReadSize;
 
         byte[] outputBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
         byte[] errorBuffer = ArrayPool<byte>.Shared.Rent(BufferHelper.InitialRentedBufferSize);
         int outputStartIndex = 0, outputEndIndex = 0;
         int errorStartIndex = 0, errorEndIndex = 0;
+        Decoder? outputDecoder = _options.StandardStreamsUnbuffered ? encoding.GetDecoder() : null;
+        Decoder? errorDecoder = _options.StandardStreamsUnbuffered ? encoding.GetDecoder() : null;
 
         SafeFileHandle? parentOutputHandle = null, childOutputHandle = null, parentErrorHandle = null, childErrorHandle = null;
         MemoryHandle outputPin = outputBuffer.AsMemory().Pin();
         MemoryHandle errorPin = errorBuffer.AsMemory().Pin();
         try
         {
-            using SafeFileHandle inputHandle = Console.OpenStandardInputHandle();
+            using SafeFileHandle inputHandle = OpenStandardInputHandle();
             File.CreatePipe(out parentOutputHandle, out childOutputHandle, asyncRead: true);
             File.CreatePipe(out parentErrorHandle, out childErrorHandle, asyncRead: true);
 
@@ -37,7 +40,7 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
             using OverlappedContext outputContext = OverlappedContext.Allocate();
             using OverlappedContext errorContext = OverlappedContext.Allocate();
 
-            _processId = processHandle.ProcessId;
+            OnStarted(processHandle.ProcessId);
 
             // First of all, we need to drain STD OUT and ERR pipes.
             // We don't optimize for reading one (when other is closed).
@@ -47,8 +50,8 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
             unsafe
             {
                 // Issue first reads.
-                Interop.Kernel32.ReadFile(parentOutputHandle, (byte*)outputPin.Pointer, outputBuffer.Length, IntPtr.Zero, outputContext.GetOverlapped());
-                Interop.Kernel32.ReadFile(parentErrorHandle, (byte*)errorPin.Pointer, errorBuffer.Length, IntPtr.Zero, errorContext.GetOverlapped());
+                Interop.Kernel32.ReadFile(parentOutputHandle, (byte*)outputPin.Pointer, Math.Min(readSize, outputBuffer.Length), IntPtr.Zero, outputContext.GetOverlapped());
+                Interop.Kernel32.ReadFile(parentErrorHandle, (byte*)errorPin.Pointer, Math.Min(readSize, errorBuffer.Length), IntPtr.Zero, errorContext.GetOverlapped());
             }
 
             while (!parentOutputHandle.IsClosed || !parentErrorHandle.IsClosed)
@@ -74,23 +77,40 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                     int bytesRead = currentContext.GetOverlappedResult(currentFileHandle);
                     if (bytesRead > 0)
                     {
+                        RecordBytesRead(bytesRead, isError);
+
                         int remaining = bytesRead + currentEndIndex - currentStartIndex;
                         int startIndex = currentStartIndex;
-                        do
+                        if (_options.StandardStreamsUnbuffered)
                         {
-                            int lineEnd = currentBuffer.AsSpan(startIndex, remaining).IndexOf((byte)'\n');
-                            if (lineEnd == -1)
+                            string chunk = DecodeUnbuffered(isError ? errorDecoder! : outputDecoder!, currentBuffer.AsSpan(currentEndIndex, bytesRead), flush: false);
+                            if (chunk.Length > 0)
                             {
-                                break;
+                                yield return new ProcessOutputLine(chunk, standardError: isError);
                             }
 
-                            yield return new ProcessOutputLine(
-                                encoding.GetString(currentBuffer.AsSpan(startIndex, lineEnd - 1)), // Exclude '\r'
-                                standardError: isError);
+                            // Nothing is kept in the buffer, the next read starts from the beginning.
+                            startIndex = 0;
+                            remaining = 0;
+                        }
+                        else
+                        {
+                            do
+                            {
+                                int lineEnd = currentBuffer.AsSpan(startIndex, remaining).IndexOf((byte)'\n');
+                                if (lineEnd == -1)
+                                {
+                                    break;
+                                }
+
+                                yield return new ProcessOutputLine(
+                                    encoding.GetString(currentBuffer.AsSpan(startIndex, lineEnd - 1)), // Exclude '\r'
+                                    standardError: isError);
 
-                            startIndex += lineEnd + 1;
-                            remaining -= lineEnd + 1;
-                        } while (remaining > 0);
+                                startIndex += lineEnd + 1;
+                                remaining -= lineEnd + 1;
+                            } while (remaining > 0);
+                        }
 
                         currentStartIndex = startIndex;
                         currentEndIndex = currentStartIndex + remaining;
@@ -138,7 +158,7 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                         unsafe
                         {
                             void* pinPointer = isError ? errorPin.Pointer : outputPin.Pointer;
-                            int sliceLength = currentBuffer.Length - currentEndIndex;
+                            int sliceLength = Math.Min(readSize, currentBuffer.Length - currentEndIndex);
                             byte* targetPointer = (byte*)pinPointer + currentEndIndex;
 
                             Interop.Kernel32.ReadFile(currentFileHandle, targetPointer, sliceLength, IntPtr.Zero, currentContext.GetOverlapped());
@@ -162,6 +182,14 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                                 outputStartIndex = outputEndIndex = 0;
                             }
                         }
+                        else if (_options.StandardStreamsUnbuffered)
+                        {
+                            string chunk = DecodeUnbuffered(isError ? errorDecoder! : outputDecoder!, ReadOnlySpan<byte>.Empty, flush: true);
+                            if (chunk.Length > 0)
+                            {
+                                yield return new ProcessOutputLine(chunk, standardError: isError);
+                            }
+                        }
 
                         if (!currentFileHandle.IsClosed)
                         {
@@ -187,7 +215,7 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                     ? processHandle.WaitForExit()
                     : processHandle.WaitForExitOrKillOnTimeout(remaining);
             }
-            _exitStatus = exitStatus;
+            OnExited(exitStatus);
             yield break;
         }
         finally
@@ -1,11 +1,14 @@
 ﻿using System;
 using System.Collections;
 using System.Collections.Generic;
+using System.Diagnostics;
 using System.IO;
+using System.Runtime.CompilerServices;
 using System.Threading;
 using System.Threading.Tasks;
 using Microsoft.Win32.SafeHandles;
 using System.Text;
+using System.Buffers;
 
 namespace System.TBA;
 
@@ -19,6 +22,10 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
     private readonly Encoding? _encoding;
     private int? _processId;
     private ProcessExitStatus? _exitStatus;
+    private long _lastOutputTimestamp;
+    private long _standardOutputBytesRead;
+    private long _standardErrorBytesRead;
+    private readonly TaskCompletionSource _exited = new(TaskCreationOptions.RunContinuationsAsynchronously);
 
     internal ProcessOutputLines(ProcessStartOptions options, TimeSpan? timeout, Encoding? encoding)
     {
@@ -41,6 +48,18 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
     /// <remarks>Throws <see cref="InvalidOperationException"/> if the process has not exited yet.</remarks>
     public ProcessExitStatus ExitStatus => _exitStatus ?? throw new InvalidOperationException("Process has not exited yet.");
 
+    /// <summary>
+    /// Gets the total number of bytes read from the standard output of the process so far.
+    /// </summary>
+    /// <remarks>It's updated while the output is being consumed and can be read from any thread, e.g. to report throughput.</remarks>
+    public long StandardOutputBytesRead => Interlocked.Read(ref _standardOutputBytesRead);
+
+    /// <summary>
+    /// Gets the total number of bytes read from the standard error of the process so far.
+    /// </summary>
+    /// <remarks>It's updated while the output is being consumed and can be read from any thread, e.g. to report throughput.</remarks>
+    public long StandardErrorBytesRead => Interlocked.Read(ref _standardErrorBytesRead);
+
     // Design: prevent the deadlocks: the user has to consume output lines, otherwise the process is not even started.
     public async IAsyncEnumerator<ProcessOutputLine> GetAsyncEnumerator(CancellationToken cancellationToken = default)
     {
@@ -48,19 +67,30 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
         File.CreatePipe(out SafeFileHandle parentOutputHandle, out SafeFileHandle childOutputHandle, asyncRead: OperatingSystem.IsWindows());
         File.CreatePipe(out SafeFileHandle parentErrorHandle, out SafeFileHandle childErrorHandle, asyncRead: OperatingSystem.IsWindows());
 
-        using SafeFileHandle inputHandle = Console.OpenStandardInputHandle();
+        using SafeFileHandle inputHandle = OpenStandardInputHandle();
         using (parentOutputHandle)
         using (childOutputHandle)
         using (childErrorHandle)
         using (parentErrorHandle)
         {
             using SafeChildProcessHandle procHandle = SafeChildProcessHandle.Start(_options, inputHandle, childOutputHandle, childErrorHandle);
-            _processId = procHandle.ProcessId;
+            OnStarted(procHandle.ProcessId);
 
             // NOTE: we could get current console Encoding here, it's omitted for the sake of simplicity of the proof of concept.
             Encoding encoding = _encoding ?? Encoding.UTF8;
-            using StreamReader outputReader = new(StreamHelper.CreateReadStream(parentOutputHandle, cancellationToken), encoding);
-            using StreamReader errorReader = new(StreamHelper.CreateReadStream(parentErrorHandle, cancellationToken), encoding);
+            using StreamReader outputReader = new(new ReadActivityStream(StreamHelper.CreateReadStream(parentOutputHandle, cancellationToken), bytesRead => RecordBytesRead(bytesRead, isError: false)), encoding);
+            using StreamReader errorReader = new(new ReadActivityStream(StreamHelper.CreateReadStream(parentErrorHandle, cancellationToken), bytesRead => RecordBytesRead(bytesRead, isError: true)), encoding);
+
+            if (_options.StandardStreamsUnbuffered)
+            {
+                await foreach (ProcessOutputLine chunk in ReadUnbufferedAsync(outputReader, errorReader, cancellationToken))
+                {
+                    yield return chunk;
+                }
+
+                OnExited(await GetExitStatusAsync(procHandle, cancellationToken));
+                yield break;
+            }
 
             Task<string?> readOutput = outputReader.ReadLineAsync(cancellationToken).AsTask();
             Task<string?> readError = errorReader.ReadLineAsync(cancellationToken).AsTask();
@@ -126,13 +156,136 @@ public partial class ProcessOutputLines : IAsyncEnumerable<ProcessOutputLine>, I
                 moreData = await remaining.ReadLineAsync(cancellationToken);
             }
 
-            if (!procHandle.TryGetExitStatus(canceled: false, out ProcessExitStatus? exitStatus))
+            OnExited(await GetExitStatusAsync(procHandle, cancellationToken));
+        }
+    }
+
+    IEnumerator IEnumerable.GetEnumerator() => GetEnumerator();
+
+    /// <summary>
+    /// Waits until no output has arrived on standard output and standard error for the specified period, which indicates that the process appears to be stuck.
+    /// </summary>
+    /// <param name="idlePeriod">The period without any output after which the process is considered idle.</param>
+    /// <param name="cancellationToken">The cancellation token to cancel the wait.</param>
+    /// <returns><c>true</c> if no output has arrived for <paramref name="idlePeriod"/>; <c>false</c> if the process has exited first.</returns>
+    /// <exception cref="ArgumentOutOfRangeException">Thrown when <paramref name="idlePeriod"/> is not positive.</exception>
+    /// <exception cref="InvalidOperationException">Thrown when the process has not started yet.</exception>
+    /// <remarks>
+    /// The output is observed by the enumeration, so this method is meant to be used (e.g. by a watchdog) while the output is being consumed.
+    /// The idle timer starts when the process is started and is reset every time any bytes are read, even if they don't form a complete line.
+    /// </remarks>
+    public async Task<bool> WaitForOutputIdleAsync(TimeSpan idlePeriod, CancellationToken cancellationToken = default)
+    {
+        ArgumentOutOfRangeException.ThrowIfLessThanOrEqual(idlePeriod, TimeSpan.Zero);
+        if (_processId is null)
+        {
+            throw new InvalidOperationException("Process has not started yet.");
+        }
+
+        while (!_exited.Task.IsCompleted)
+        {
+            TimeSpan idleFor = Stopwatch.GetElapsedTime(Volatile.Read(ref _lastOutputTimestamp));
+            if (idleFor >= idlePeriod)
             {
-                exitStatus = await procHandle.WaitForExitAsync(cancellationToken);
+                return true;
             }
-            _exitStatus = exitStatus;
+
+            // The output could have arrived in the meantime, so we re-check the timestamp once the delay is over.
+            Task delay = Task.Delay(idlePeriod - idleFor, cancellationToken);
+            await Task.WhenAny(delay, _exited.Task);
+            cancellationToken.ThrowIfCancellationRequested();
         }
+
+        return false;
     }
 
-    IEnumerator IEnumerable.GetEnumerator() => GetEnumerator();
+    private void OnStarted(int processId)
+    {
+        RecordOutputActivity();
+        _processId = processId;
+    }
+
+    private void OnExited(ProcessExitStatus exitStatus)
+    {
+        _exitStatus = exitStatus;
+        _exited.TrySetResult();
+    }
+
+    private void RecordOutputActivity() => Volatile.Write(ref _lastOutputTimestamp, Stopwatch.GetTimestamp());
+
+    private void RecordBytesRead(int bytesRead, bool isError)
+    {
+        Interlocked.Add(ref isError ? ref _standardErrorBytesRead : ref _standardOutputBytesRead, bytesRead);
+        RecordOutputActivity();
+    }
+
+    private SafeFileHandle OpenStandardInputHandle()
+    {
+        SafeFileHandle parentInputHandle = Console.OpenStandardInputHandle();
+        if (_options.StartWithNulStdinToAvoidTerminalSteal && parentInputHandle.IsTerminal())
+        {
+            parentInputHandle.Dispose();
+            return File.OpenNullFileHandle();
+        }
+
+        return parentInputHandle;
+    }
+
+    private static async Task<ProcessExitStatus> GetExitStatusAsync(SafeChildProcessHandle procHandle, CancellationToken cancellationToken)
+    {
+        if (!procHandle.TryGetExitStatus(canceled: false, out ProcessExitStatus? exitStatus))
+        {
+            exitStatus = await procHandle.WaitForExitAsync(cancellationToken);
+        }
+        return exitStatus;
+    }
+
+    // Delivers the text as soon as it's read from the pipe, without waiting for the end of line.
+    private static async IAsyncEnumerable<ProcessOutputLine> ReadUnbufferedAsync(StreamReader outputReader, StreamReader errorReader,
+        [EnumeratorCancellation] CancellationToken cancellationToken)
+    {
+        char[] outputChars = new char[BufferHelper.UnbufferedReadSize];
+        char[] errorChars = new char[BufferHelper.UnbufferedReadSize];
+
+        Task<int>? readOutput = outputReader.ReadAsync(outputChars, cancellationToken).AsTask();
+        Task<int>? readError = errorReader.ReadAsync(errorChars, cancellationToken).AsTask();
+
+        while (readOutput is not null || readError is not null)
+        {
+            Task<int> completedTask = readOutput is null
+                ? readError!
+                : readError is null ? readOutput : await Task.WhenAny(readOutput, readError);
+            bool isError = completedTask == readError;
+
+            int charsRead = await completedTask;
+            if (charsRead > 0)
+            {
+                yield return new(new string(isError ? errorChars : outputChars, 0, charsRead), isError);
+            }
+
+            if (isError)
+            {
+                readError = charsRead > 0 ? errorReader.ReadAsync(errorChars, cancellationToken).AsTask() : null;
+            }
+            else
+            {
+                readOutput = charsRead > 0 ? outputReader.ReadAsync(outputChars, cancellationToken).AsTask() : null;
+            }
+        }
+    }
+
+    // Decodes a chunk of bytes, the decoder keeps incomplete multi-byte sequences for the next call.
+    private static string DecodeUnbuffered(Decoder decoder, ReadOnlySpan<byte> bytes, bool flush)
+    {
+        char[] chars = ArrayPool<char>.Shared.Rent(decoder.GetCharCount(bytes, flush));
+        try
+        {
+            int charsCount = decoder.GetChars(bytes, chars, flush);
+            return new string(chars, 0, charsCount);
+        }
+        finally
+        {
+            ArrayPool<char>.Shared.Return(chars);
+        }
+    }
 }
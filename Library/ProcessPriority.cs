@@ -0,0 +1,29 @@
+namespace System.TBA;
+
+/// <summary>
+/// Specifies the scheduling priority of a process.
+/// </summary>
+/// <remarks>
+/// On Windows, the values map to priority classes. On Unix, they map to nice values:
+/// 19, 10, 0, -6, -11 and -19 respectively. Raising the priority above <see cref="Normal"/> on Unix usually requires elevated privileges.
+/// </remarks>
+public enum ProcessPriority
+{
+    /// <summary>The process runs only when the system is idle.</summary>
+    Idle,
+
+    /// <summary>The priority is between <see cref="Idle"/> and <see cref="Normal"/>.</summary>
+    BelowNormal,
+
+    /// <summary>The default priority.</summary>
+    Normal,
+
+    /// <summary>The priority is between <see cref="Normal"/> and <see cref="High"/>.</summary>
+    AboveNormal,
+
+    /// <summary>The process performs time-critical tasks.</summary>
+    High,
+
+    /// <summary>The highest possible priority.</summary>
+    RealTime,
+}
@@ -1,7 +1,10 @@
 ﻿using System.Collections;
 using System.Collections.Generic;
+using System.Collections.ObjectModel;
+using System.Diagnostics;
 using System.IO;
 using System.Runtime.InteropServices;
+using System.Threading;
 
 namespace System.TBA;
 
@@ -14,11 +17,17 @@ public sealed class ProcessStartOptions
 #if WINDOWS
     private static string? _systemDirectory;
 #endif
+    private static long _fileNameResolutionCount;
 
     private readonly string _fileName;
     private IList<string>? _arguments;
     private Dictionary<string, string?>? _envVars;
     private IList<SafeHandle>? _inheritedHandles;
+    private EnvironmentCaseSensitivity _environmentCaseSensitivityOverride;
+    private int? _outputReadBufferSize;
+    private long? _capturedOutputSpillThresholdBytes;
+    private TimeSpan? _fileNameResolutionCacheTimeToLive;
+    private TimeSpan _shutdownGracePeriod = TimeSpan.FromSeconds(5);
 
     // More or less same as ProcessStartInfo
     /// <summary>
@@ -30,12 +39,65 @@ public sealed class ProcessStartOptions
     /// </summary>
     public IList<string> Arguments { get => _arguments ??= new List<string>(); set => _arguments = value; }
     /// <summary>
+    /// Gets a read-only snapshot of the command-line arguments that will be passed to the application.
+    /// </summary>
+    /// <remarks>
+    /// Every read copies <see cref="Arguments"/>, so the returned list does not change when they are modified afterwards.
+    /// The same way, the arguments are copied when the process is started,
+    /// so modifying them afterwards does not affect the already started process.
+    /// </remarks>
+    public IReadOnlyList<string> EffectiveArguments => _arguments is null or { Count: 0 } ? ReadOnlyCollection<string>.Empty : new ReadOnlyCollection<string>([.. _arguments]);
+    /// <summary>
     /// Gets the environment variables that apply to this process and its child processes.
     /// </summary>
     /// <remarks>
+    /// <para>
     /// By default, the environment is a copy of the current process environment.
+    /// </para>
+    /// <para>
+    /// It only defines what the child sees. <see cref="FileName"/> is always resolved with the PATH of the current process,
+    /// so clearing the environment (or removing PATH from it) does not affect finding the executable.
+    /// </para>
     /// </remarks>
-    public IDictionary<string, string?> Environment => _envVars ??= CreateEnvironmentCopy();
+    public IDictionary<string, string?> Environment => _envVars ??= CreateEnvironmentCopy(EnvironmentNameComparer);
+    /// <summary>
+    /// Gets or sets how the names of the variables in <see cref="Environment"/> are compared.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// This is meant for cross-platform testing and compatibility scenarios only (e.g. reproducing Windows behavior on Unix)
+    /// and normally should not be changed. The default is <see cref="EnvironmentCaseSensitivity.PlatformDefault"/>:
+    /// names are case-insensitive on Windows and case-sensitive on Unix.
+    /// </para>
+    /// <para>
+    /// When the comparison becomes case-insensitive, variables that differ only by case are merged into one and the last one wins.
+    /// On Windows, the environment block is always sorted case-insensitively as required by the OS,
+    /// variables that differ only by case (possible with <see cref="EnvironmentCaseSensitivity.CaseSensitive"/>) are all passed to the child process.
+    /// </para>
+    /// </remarks>
+    public EnvironmentCaseSensitivity EnvironmentCaseSensitivityOverride
+    {
+        get => _environmentCaseSensitivityOverride;
+        set
+        {
+            if (value is < EnvironmentCaseSensitivity.PlatformDefault or > EnvironmentCaseSensitivity.CaseInsensitive)
+            {
+                throw new ArgumentOutOfRangeException(nameof(value));
+            }
+
+            _environmentCaseSensitivityOverride = value;
+            if (_envVars is not null)
+            {
+                // Rebuild the dictionary, so the already added variables follow the new rules.
+                Dictionary<string, string?> envVars = new(EnvironmentNameComparer);
+                foreach (KeyValuePair<string, string?> pair in _envVars)
+                {
+                    envVars[pair.Key] = pair.Value;
+                }
+                _envVars = envVars;
+            }
+        }
+    }
 
     /// <summary>
     /// Gets a list of handles that will be inherited by the child process.
@@ -54,6 +116,11 @@ public sealed class ProcessStartOptions
     /// On Unix, the implementation will modify the copy of every handle in the child process
     /// by removing FD_CLOEXEC flag. It happens after the fork and before the exec, so it does not affect parent process.
     /// </para>
+    /// <para>
+    /// The list is copied when the process is started, modifying it afterwards affects only the processes started later.
+    /// The handles remain owned by the caller: they are never disposed by the library and can be closed as soon as the start returns,
+    /// without affecting the copies inherited by the running child.
+    /// </para>
     /// </remarks>
     public IList<SafeHandle> InheritedHandles { get => _inheritedHandles ??= new List<SafeHandle>(); set => _inheritedHandles = value; }
     
@@ -62,6 +129,22 @@ public sealed class ProcessStartOptions
     /// </summary>
     public string? WorkingDirectory { get; set; }
     /// <summary>
+    /// Gets or sets a value indicating whether a <see cref="WorkingDirectory"/> starting with "./" is relative to the directory of the executable.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// When set to true, a working directory like "./data" is resolved against the directory of the resolved executable
+    /// (e.g. "/opt/app/data" for "/opt/app/tool"), which is handy for applications that ship their data next to the executable.
+    /// On Windows, ".\" prefix is supported as well.
+    /// </para>
+    /// <para>
+    /// Absolute paths and relative paths without the "./" prefix (e.g. "data" or "../data") are not affected
+    /// and keep being resolved by the OS against the current directory of the parent process.
+    /// The default is false.
+    /// </para>
+    /// </remarks>
+    public bool WorkingDirectoryRelativeToExecutable { get; set; }
+    /// <summary>
     /// Gets or sets a value indicating whether to start the process in a new window.
     /// </summary>
     public bool CreateNoWindow { get; set; }
@@ -87,13 +170,342 @@ public sealed class ProcessStartOptions
     /// </remarks>
     public bool CreateNewProcessGroup { get; set; }
 
-    // Internal property to check if environment was explicitly set
-    internal bool HasEnvironmentBeenAccessed => _envVars != null;
+    /// <summary>
+    /// Gets or sets the token that shuts down the started process when it's canceled, e.g. the host shutdown token of a service.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// The same token can be used for all the children, so a single cancellation stops every one of them that is still running.
+    /// The shutdown is graceful first: SIGTERM is sent on Unix, on Windows WM_CLOSE is posted to the main windows (see <see cref="SafeChildProcessHandle.CloseMainWindow"/>)
+    /// and CTRL_BREAK_EVENT is sent when <see cref="CreateNewProcessGroup"/> is true. The process that is still running after <see cref="ShutdownGracePeriod"/>
+    /// (or right away on Windows, when there was no way to ask it to exit) is killed.
+    /// </para>
+    /// <para>
+    /// When <see cref="CreateNewProcessGroup"/> is true, both steps target the entire process group, so the whole tree is shut down.
+    /// The shutdown runs in the background: cancelling the token does not block and the waits of the process return once it has exited.
+    /// It does not apply to detached processes and it's not performed for processes that have exited or whose handle has been disposed.
+    /// </para>
+    /// </remarks>
+    public CancellationToken ShutdownToken { get; set; }
+
+    /// <summary>
+    /// Gets or sets how long the process has to exit on its own after <see cref="ShutdownToken"/> is canceled before it's killed. The default is 5 seconds.
+    /// </summary>
+    /// <exception cref="ArgumentOutOfRangeException">The value is negative.</exception>
+    public TimeSpan ShutdownGracePeriod
+    {
+        get => _shutdownGracePeriod;
+        set
+        {
+            ArgumentOutOfRangeException.ThrowIfLessThan(value, TimeSpan.Zero, nameof(value));
+
+            _shutdownGracePeriod = value;
+        }
+    }
+
+    /// <summary>
+    /// Gets or sets a value indicating whether the child process should be traced by the parent with ptrace. Linux only.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// The child calls ptrace(PTRACE_TRACEME) right before exec, so exec stops it with SIGTRAP before the first instruction of the new program runs.
+    /// <see cref="SafeChildProcessHandle.Start"/> returns once the child has reached this initial stop, which gives the parent a chance
+    /// to set up tracing (e.g. PTRACE_SETOPTIONS). Subsequent stops can be observed with <see cref="SafeChildProcessHandle.WaitForRawStatus"/>
+    /// and <see cref="SafeChildProcessHandle.DetachTracer"/> lets the child run untraced.
+    /// </para>
+    /// <para>
+    /// ptrace requests are accepted only from the thread that started the process. Starting the process throws
+    /// <see cref="PlatformNotSupportedException"/> on other platforms. The default is false.
+    /// </para>
+    /// </remarks>
+    public bool TraceChildWithPtrace { get; set; }
+
+    /// <summary>
+    /// Gets or sets a value indicating whether the output of the process should be delivered as soon as it's read,
+    /// without waiting for a complete line.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// By default, <see cref="ProcessOutputLines"/> buffers the output until a new line is received.
+    /// When this property is set to true, every chunk of text read from the pipe is delivered immediately
+    /// as a <see cref="ProcessOutputLine"/>, which may contain a part of a line or multiple lines (new line characters are preserved).
+    /// </para>
+    /// <para>
+    /// It trades throughput for latency and is useful for interactive child processes like REPLs,
+    /// which print prompts without a trailing new line.
+    /// </para>
+    /// </remarks>
+    public bool StandardStreamsUnbuffered { get; set; }
+
+    /// <summary>
+    /// Gets or sets a value indicating whether the standard input of the child process should be connected to the null device
+    /// instead of the parent's standard input when the parent's standard input is a terminal.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// A child process that reads from standard input steals keystrokes from an interactive parent.
+    /// When this property is set to true and the parent's standard input is a terminal (a console on Windows),
+    /// APIs that implicitly connect the parent's standard input (<see cref="ChildProcess.StreamOutputLines"/>)
+    /// connect the child's standard input to the null device, so the child gets EOF when it reads from it.
+    /// </para>
+    /// <para>
+    /// Explicitly provided input handles and <see cref="ChildProcess.Inherit"/> (which requests inheritance) are not affected.
+    /// The default is false to preserve the existing behavior, it may change in the future.
+    /// </para>
+    /// </remarks>
+    public bool StartWithNulStdinToAvoidTerminalSteal { get; set; }
+
+    /// <summary>
+    /// Gets or sets the size-based rotation policy of the output file used by <see cref="ChildProcess.RedirectToFiles"/>
+    /// and <see cref="ChildProcess.RedirectToFilesAsync"/>. When null (the default), no rotation is performed.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// By default, the child process writes directly to the file descriptor of the output file and the parent is not involved at all.
+    /// Rotation requires the parent to be in charge of the file, so when this property is set, the child writes to a pipe
+    /// and the parent copies its content to the file. It's more expensive, but the only way to rotate the file of a running child.
+    /// </para>
+    /// <para>
+    /// The output file is opened in append mode. Standard error is rotated only when it's redirected to the same file as standard output.
+    /// </para>
+    /// </remarks>
+    public FileRotationOptions? StandardOutputToFileWithRotation { get; set; }
+
+    /// <summary>
+    /// Gets or sets the maximum number of bytes read from the standard output and error pipes with a single read by
+    /// <see cref="ChildProcess.CaptureOutput"/> and <see cref="ChildProcess.CaptureOutputAsync"/>.
+    /// When null (the default), every read fills as much of the capture buffer as is available.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// It's independent of the size of the pipe buffer, which is controlled by the OS.
+    /// Large values reduce the number of reads (syscalls) for chatty child processes,
+    /// while small values reduce the initial memory footprint of the capture.
+    /// </para>
+    /// <para>
+    /// The captured output is not limited by this value, the buffer keeps growing as needed.
+    /// </para>
+    /// </remarks>
+    /// <exception cref="ArgumentOutOfRangeException">The value is zero or negative.</exception>
+    public int? OutputReadBufferSize
+    {
+        get => _outputReadBufferSize;
+        set
+        {
+            if (value.HasValue)
+            {
+                ArgumentOutOfRangeException.ThrowIfNegativeOrZero(value.Value, nameof(value));
+            }
+
+            _outputReadBufferSize = value;
+        }
+    }
+
+    /// <summary>
+    /// Gets or sets the number of bytes of standard output (and, separately, of standard error) that <see cref="ChildProcess.CaptureSeekableOutput"/>
+    /// and <see cref="ChildProcess.CaptureSeekableOutputAsync"/> keep in memory before moving it to a temporary file. When null (the default), the output is always kept in memory.
+    /// </summary>
+    /// <remarks>
+    /// Spilling avoids running out of memory for huge outputs: once the threshold is exceeded, only the read buffer stays in memory.
+    /// The file is created in <see cref="Path.GetTempPath"/> and deleted when the <see cref="SeekableProcessOutput"/> is disposed.
+    /// Zero sends any non-empty output to a file.
+    /// </remarks>
+    /// <exception cref="ArgumentOutOfRangeException">The value is negative.</exception>
+    public long? CapturedOutputSpillThresholdBytes
+    {
+        get => _capturedOutputSpillThresholdBytes;
+        set
+        {
+            if (value.HasValue)
+            {
+                ArgumentOutOfRangeException.ThrowIfNegative(value.Value, nameof(value));
+            }
+
+            _capturedOutputSpillThresholdBytes = value;
+        }
+    }
+
+    /// <summary>
+    /// Gets or sets a value indicating whether <see cref="FileName"/> is an already resolved, fully qualified path
+    /// that is passed to the OS as-is, without any lookup. It's true for options created by <see cref="ResolvePath"/>.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// It's meant for hot loops where the caller has resolved the executable once: no directories are searched,
+    /// no file system calls are made and <see cref="FileNameResolutionCacheTimeToLive"/> is ignored.
+    /// </para>
+    /// <para>
+    /// The path is not verified either, so when the executable does not exist, the start fails at spawn with a <see cref="System.ComponentModel.Win32Exception"/>
+    /// saying that the pre-resolved executable could not be found.
+    /// </para>
+    /// </remarks>
+    /// <exception cref="ArgumentException">Thrown when set to true and <see cref="FileName"/> is not a fully qualified path.</exception>
+    public bool PreResolvedExecutable
+    {
+        get => IsFileNameResolved;
+        set
+        {
+            if (value && !Path.IsPathFullyQualified(_fileName))
+            {
+                throw new ArgumentException($"'{_fileName}' is not a fully qualified path, so it can't be marked as pre-resolved.", nameof(value));
+            }
+
+            IsFileNameResolved = value;
+        }
+    }
+
+    /// <summary>
+    /// Gets or sets how long the resolved path of <see cref="FileName"/> can be reused by subsequent launches.
+    /// When null (the default), the file name is resolved on every launch.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// Resolving a bare file name (see <see cref="ResolvePath"/>) checks multiple directories and hits the file system on every launch.
+    /// When this property is set, the resolved path is stored in a small process-wide cache keyed by the file name,
+    /// the PATH environment variable and the current directory, so hot loops launching the same command avoid redundant lookups.
+    /// </para>
+    /// <para>
+    /// It's opt-in, because a cached path can become stale: an executable installed to a directory that comes earlier in PATH
+    /// is not picked up until the entry expires. When the cached executable can't be found anymore, the entry is evicted
+    /// and the next launch resolves the file name again.
+    /// </para>
+    /// </remarks>
+    /// <exception cref="ArgumentOutOfRangeException">Thrown when the value is zero or negative.</exception>
+    public TimeSpan? FileNameResolutionCacheTimeToLive
+    {
+        get => _fileNameResolutionCacheTimeToLive;
+        set
+        {
+            if (value.HasValue)
+            {
+                ArgumentOutOfRangeException.ThrowIfLessThanOrEqual(value.Value, TimeSpan.Zero, nameof(value));
+            }
+
+            _fileNameResolutionCacheTimeToLive = value;
+        }
+    }
+
+    /// <summary>
+    /// Gets or sets a callback that is invoked synchronously right before the process is started and can veto the launch.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// The callback gets the fully-resolved launch information: the absolute path of the executable
+    /// (never the bare <see cref="FileName"/>, so PATH tricks can't bypass the audit), the arguments and the working directory.
+    /// When it returns false or throws, the process is not started and a <see cref="LaunchDeniedException"/> is thrown instead.
+    /// </para>
+    /// <para>
+    /// It allows security layers to centrally block certain executables or arguments. The default is null (no audit).
+    /// </para>
+    /// </remarks>
+    public Func<LaunchInfo, bool>? LaunchAuditCallback { get; set; }
+
+    /// <summary>
+    /// Gets or sets a value indicating whether the W3C trace context of the current <see cref="Activity"/>
+    /// is passed to the child process in the TRACEPARENT and TRACESTATE environment variables.
+    /// </summary>
+    /// <remarks>
+    /// <para>
+    /// It allows trace-aware children (e.g. instrumented with OpenTelemetry) to continue the trace of the parent across the process boundary.
+    /// The variables are captured from <see cref="Activity.Current"/> when the process is started, so the same options can be reused by multiple activities.
+    /// They override the values inherited from the current process or set in <see cref="Environment"/>, which is not modified.
+    /// </para>
+    /// <para>
+    /// Nothing is added when there is no current activity or it does not use <see cref="ActivityIdFormat.W3C"/>. The default is false.
+    /// </para>
+    /// </remarks>
+    public bool PropagateOpenTelemetryContext { get; set; }
+
+    /// <summary>
+    /// Gets or sets the human-readable meanings of the exit codes of the process, e.g. 23 = "partial transfer" for rsync.
+    /// </summary>
+    /// <remarks>
+    /// When the process exits with a non-zero code that has a description, <see cref="ProcessOutput.GetExitCodeOrThrowWithDiagnostics"/>
+    /// includes it in the message of the thrown <see cref="ProcessExitException"/> and in <see cref="ProcessExitException.ExitCodeDescription"/>.
+    /// The default is null.
+    /// </remarks>
+    public IReadOnlyDictionary<int, string>? ExitCodeDescriptions { get; set; }
+
+    /// <summary>
+    /// Gets or sets the clock used to record when the process was started and when its exit was observed.
+    /// When null (the default), <see cref="TimeProvider.System"/> is used.
+    /// </summary>
+    /// <remarks>
+    /// <see cref="SafeChildProcessHandle.Elapsed"/> is computed from <see cref="TimeProvider.GetTimestamp"/>,
+    /// which is monotonic, so it's not affected by adjustments of the system clock.
+    /// <see cref="TimeProvider.GetUtcNow"/> is used only for <see cref="SafeChildProcessHandle.StartTime"/> and <see cref="SafeChildProcessHandle.ExitTime"/>.
+    /// </remarks>
+    public TimeProvider? StartTimeMonotonicSource { get; set; }
+
+    internal int InitialOutputBufferSize => _outputReadBufferSize ?? BufferHelper.InitialRentedBufferSize;
+
+    internal int MaxOutputReadSize => _outputReadBufferSize ?? int.MaxValue;
+
+    internal int StreamedOutputReadSize => StandardStreamsUnbuffered ? BufferHelper.UnbufferedReadSize : int.MaxValue;
+
+    internal string? GetEffectiveWorkingDirectory(ReadOnlySpan<char> resolvedFileName)
+    {
+        string? workingDirectory = WorkingDirectory;
+        if (!WorkingDirectoryRelativeToExecutable || workingDirectory is null || !IsExplicitlyRelative(workingDirectory))
+        {
+            return workingDirectory;
+        }
+
+        // GetFullPath removes the "./" segment.
+        return Path.GetFullPath(Path.Join(Path.GetDirectoryName(resolvedFileName), workingDirectory));
+
+        static bool IsExplicitlyRelative(string path)
+            => path.StartsWith("./", StringComparison.Ordinal)
+                || (OperatingSystem.IsWindows() && path.StartsWith(".\\", StringComparison.Ordinal));
+    }
+
+    internal StringComparer EnvironmentNameComparer => _environmentCaseSensitivityOverride switch
+    {
+        EnvironmentCaseSensitivity.CaseSensitive => StringComparer.Ordinal,
+        EnvironmentCaseSensitivity.CaseInsensitive => StringComparer.OrdinalIgnoreCase,
+        _ => OperatingSystem.IsWindows() ? StringComparer.OrdinalIgnoreCase : StringComparer.Ordinal,
+    };
+
+    // The environment of the child process, null means the current environment is inherited as-is.
+    internal IDictionary<string, string?>? GetEffectiveEnvironment()
+    {
+        if (!PropagateOpenTelemetryContext || Activity.Current is not { IdFormat: ActivityIdFormat.W3C } activity)
+        {
+            return _envVars;
+        }
+
+        // Don't modify the user's dictionary, the options can be reused with a different activity.
+        Dictionary<string, string?> environment = _envVars is null
+            ? CreateEnvironmentCopy(EnvironmentNameComparer)
+            : new(_envVars, _envVars.Comparer);
+
+        environment["TRACEPARENT"] = activity.Id;
+        // null removes a stale value inherited from the current process.
+        environment["TRACESTATE"] = activity.TraceStateString;
+        return environment;
+    }
 
     // Internal property to check if inherited handles were explicitly set
     internal bool HasInheritedHandlesBeenAccessed => _inheritedHandles != null;
 
-    internal bool IsFileNameResolved { get; }
+    // The list is copied on start, so modifying it afterwards (or from the launch audit callback) does not affect the started process.
+    internal SafeHandle[] GetInheritedHandlesSnapshot() => _inheritedHandles is null ? [] : [.. _inheritedHandles];
+
+    internal bool IsFileNameResolved { get; private set; }
+
+    internal bool UsesFileNameResolutionCache => !IsFileNameResolved && _fileNameResolutionCacheTimeToLive.HasValue;
+
+    internal string GetResolvedFileName()
+    {
+        if (IsFileNameResolved)
+        {
+            return _fileName;
+        }
+
+        return _fileNameResolutionCacheTimeToLive is TimeSpan timeToLive
+            ? FileNameResolutionCache.Resolve(_fileName, timeToLive)
+            : ResolvePathInternal(_fileName);
+    }
 
     /// <summary>
     /// Initializes a new instance of the <see cref="ProcessStartOptions"/> class.
@@ -113,9 +525,125 @@ public sealed class ProcessStartOptions
         IsFileNameResolved = isResolved;
     }
 
-    private static Dictionary<string, string?> CreateEnvironmentCopy()
+    /// <summary>
+    /// Gets the environment variables that differ between the child process and the current process.
+    /// </summary>
+    /// <returns>
+    /// The variables added, removed (set to null or removed from <see cref="Environment"/>) or changed, sorted by name.
+    /// Empty when <see cref="Environment"/> has never been accessed and no tracing context is propagated, as the child inherits the current environment then.
+    /// </returns>
+    /// <remarks>
+    /// The current process environment is read when this method is called.
+    /// Variable names are compared according to <see cref="EnvironmentCaseSensitivityOverride"/>
+    /// (case-insensitively on Windows and case-sensitively on Unix by default), values are always compared case-sensitively.
+    /// When <see cref="PropagateOpenTelemetryContext"/> is enabled, the TRACEPARENT and TRACESTATE variables of <see cref="Activity.Current"/>
+    /// are included, as the child would get them if it was started now.
+    /// </remarks>
+    public IReadOnlyList<EnvironmentVariableDifference> GetEnvironmentDiffAgainstParent()
     {
-        Dictionary<string, string?> envDict = new();
+        IDictionary<string, string?>? environment = GetEffectiveEnvironment();
+        if (environment is null)
+        {
+            return [];
+        }
+
+        StringComparer nameComparer = EnvironmentNameComparer;
+
+        Dictionary<string, string> parentVars = new(nameComparer);
+        foreach (DictionaryEntry entry in System.Environment.GetEnvironmentVariables())
+        {
+            parentVars[(string)entry.Key] = (string)entry.Value!;
+        }
+
+        Dictionary<string, string> childVars = new(nameComparer);
+        foreach (KeyValuePair<string, string?> pair in environment)
+        {
+            if (pair.Value is not null)
+            {
+                childVars[pair.Key] = pair.Value;
+            }
+        }
+
+        List<EnvironmentVariableDifference> differences = new();
+        foreach (KeyValuePair<string, string> pair in childVars)
+        {
+            if (!parentVars.TryGetValue(pair.Key, out string? parentValue))
+            {
+                differences.Add(new(pair.Key, parentValue: null, pair.Value));
+            }
+            else if (!string.Equals(parentValue, pair.Value, StringComparison.Ordinal))
+            {
+                differences.Add(new(pair.Key, parentValue, pair.Value));
+            }
+        }
+
+        foreach (KeyValuePair<string, string> pair in parentVars)
+        {
+            if (!childVars.ContainsKey(pair.Key))
+            {
+                differences.Add(new(pair.Key, pair.Value, childValue: null));
+            }
+        }
+
+        differences.Sort((x, y) => nameComparer.Compare(x.Name, y.Name));
+        return differences;
+    }
+
+    /// <summary>
+    /// Applies a layer of environment variables on top of <see cref="Environment"/>.
+    /// </summary>
+    /// <param name="layer">The variables to set. A null value removes the variable, so the child does not inherit it.</param>
+    /// <remarks>
+    /// Layers are applied in the order of the calls, so a later layer (e.g. secrets) overrides the same variables of an earlier one (e.g. base configuration).
+    /// The variables are copied right away: modifying the dictionary afterwards does not affect the options.
+    /// Names are compared according to <see cref="EnvironmentCaseSensitivityOverride"/>, not according to the comparer of <paramref name="layer"/>,
+    /// so on Windows "Path" in a later layer overrides "PATH" of an earlier one by default.
+    /// </remarks>
+    /// <exception cref="ArgumentNullException">Thrown when <paramref name="layer"/> is null.</exception>
+    public void AddEnvironmentLayer(IDictionary<string, string?> layer)
+    {
+        ArgumentNullException.ThrowIfNull(layer);
+
+        IDictionary<string, string?> environment = Environment;
+        foreach (KeyValuePair<string, string?> pair in layer)
+        {
+            environment[pair.Key] = pair.Value;
+        }
+    }
+
+    /// <summary>
+    /// Adds a directory to the paths the dynamic loader of the child process searches for shared libraries.
+    /// </summary>
+    /// <param name="directory">The directory to add.</param>
+    /// <remarks>
+    /// <para>
+    /// The directory is prepended to LD_LIBRARY_PATH on Linux (and other Unixes), DYLD_LIBRARY_PATH on macOS and PATH on Windows
+    /// in <see cref="Environment"/>, separated with <see cref="Path.PathSeparator"/> from the current value (typically inherited from the current process),
+    /// so it takes precedence over the directories that were already there. Calling it multiple times makes the last added directory searched first.
+    /// </para>
+    /// <para>
+    /// On macOS, DYLD_LIBRARY_PATH is removed by the OS when starting a binary protected by System Integrity Protection, like the ones in /usr/bin.
+    /// </para>
+    /// </remarks>
+    /// <exception cref="ArgumentException">Thrown when <paramref name="directory"/> is null or empty.</exception>
+    public void AddLibrarySearchPath(string directory)
+    {
+        ArgumentException.ThrowIfNullOrEmpty(directory);
+
+        string variableName = LibrarySearchPathVariableName;
+        Environment[variableName] = Environment.TryGetValue(variableName, out string? currentValue) && !string.IsNullOrEmpty(currentValue)
+            ? directory + Path.PathSeparator + currentValue
+            : directory;
+    }
+
+    private static string LibrarySearchPathVariableName
+        => OperatingSystem.IsWindows() ? "PATH"
+        : OperatingSystem.IsMacOS() ? "DYLD_LIBRARY_PATH"
+        : "LD_LIBRARY_PATH";
+
+    private static Dictionary<string, string?> CreateEnvironmentCopy(StringComparer nameComparer)
+    {
+        Dictionary<string, string?> envDict = new(nameComparer);
         foreach (DictionaryEntry entry in System.Environment.GetEnvironmentVariables())
         {
             envDict[(string)entry.Key] = (string?)entry.Value;
@@ -136,6 +664,9 @@ public sealed class ProcessStartOptions
         return new ProcessStartOptions(resolvedPath, isResolved: true);
     }
 
+    // The number of file names that were looked up on disk, the tests and benchmarks use it to tell a cache hit from a resolution.
+    internal static long FileNameResolutionCount => Interlocked.Read(ref _fileNameResolutionCount);
+
     internal static string ResolvePathInternal(string fileName)
     {
         ArgumentException.ThrowIfNullOrEmpty(fileName);
@@ -148,6 +679,8 @@ public sealed class ProcessStartOptions
             return fileName;
         }
 
+        Interlocked.Increment(ref _fileNameResolutionCount);
+
 #if WINDOWS
         // From: https://learn.microsoft.com/en-us/windows/win32/api/processthreadsapi/nf-processthreadsapi-createprocessw
         // "If the file name does not contain an extension, .exe is appended.
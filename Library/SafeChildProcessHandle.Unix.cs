@@ -5,6 +5,7 @@ using System.Diagnostics.CodeAnalysis;
 using System.IO;
 using System.Runtime.CompilerServices;
 using System.Runtime.InteropServices;
+using System.Text;
 using System.Threading;
 using System.Threading.Tasks;
 using System.TBA;
@@ -36,10 +37,10 @@ public partial class SafeChildProcessHandle
         };
     }
 
-    private static SafeChildProcessHandle StartCore(ProcessStartOptions options, SafeFileHandle inputHandle, SafeFileHandle outputHandle, SafeFileHandle errorHandle, bool createSuspended, bool detached)
+    private static SafeChildProcessHandle StartCore(ProcessStartOptions options, SafeHandle[] inheritedHandles, SafeFileHandle inputHandle, SafeFileHandle outputHandle, SafeFileHandle errorHandle, bool createSuspended, bool detached)
     {
         // Resolve executable path first
-        string? resolvedPath = options.IsFileNameResolved ? options.FileName : ProcessStartOptions.ResolvePathInternal(options.FileName);
+        string? resolvedPath = options.GetResolvedFileName();
         if (string.IsNullOrEmpty(resolvedPath))
         {
             throw new Win32Exception(2, $"Cannot find executable: {options.FileName}");
@@ -48,28 +49,37 @@ public partial class SafeChildProcessHandle
         // Prepare arguments array (argv)
         string[] argv = [resolvedPath, .. options.Arguments];
 
+        if (options.LaunchAuditCallback is not null)
+        {
+            AuditLaunch(options, resolvedPath, argv[1..]);
+        }
+
         // Prepare environment array (envp) only if the user has accessed it
         // If not accessed, pass null to use the current environment (environ)
-        string[]? envp = options.HasEnvironmentBeenAccessed ? UnixHelpers.GetEnvironmentVariables(options) : null;
+        IDictionary<string, string?>? environment = options.GetEffectiveEnvironment();
+        string[]? envp = environment is not null ? UnixHelpers.GetEnvironmentVariables(environment) : null;
 
         // Get file descriptors for stdin/stdout/stderr
         int stdInFd = (int)inputHandle.DangerousGetHandle();
         int stdOutFd = (int)outputHandle.DangerousGetHandle();
         int stdErrFd = (int)errorHandle.DangerousGetHandle();
 
-        return StartProcessInternal(resolvedPath, argv, envp, options, stdInFd, stdOutFd, stdErrFd, createSuspended, detached);
+        return StartProcessInternal(resolvedPath, argv, envp, options, inheritedHandles, stdInFd, stdOutFd, stdErrFd, createSuspended, detached);
     }
 
     private static unsafe SafeChildProcessHandle StartProcessInternal(string resolvedPath, string[] argv, string[]? envp,
-        ProcessStartOptions options, int stdinFd, int stdoutFd, int stderrFd, bool createSuspended, bool detached)
+        ProcessStartOptions options, SafeHandle[] inheritedHandles, int stdinFd, int stdoutFd, int stderrFd, bool createSuspended, bool detached)
     {
         // Allocate native memory BEFORE forking
         byte* resolvedPathPtr = UnixHelpers.AllocateNullTerminatedUtf8String(resolvedPath);
-        byte* workingDirPtr = UnixHelpers.AllocateNullTerminatedUtf8String(options.WorkingDirectory);
+        byte* workingDirPtr = UnixHelpers.AllocateNullTerminatedUtf8String(options.GetEffectiveWorkingDirectory(resolvedPath));
         byte** argvPtr = null;
         byte** envpPtr = null;
-        int* inheritedHandlesPtr = null;
-        int inheritedHandlesCount = 0;
+        // stdio fds are passed separately, so the array holds only the user-provided inherited handles.
+        int inheritedHandlesCount = inheritedHandles.Length;
+        bool useHeap = inheritedHandlesCount > MaxStackAllocatedHandleCount;
+        int* stackHandlesPtr = stackalloc int[useHeap ? 0 : inheritedHandlesCount];
+        int* inheritedHandlesPtr = useHeap ? (int*)NativeMemory.Alloc((nuint)inheritedHandlesCount, (nuint)sizeof(int)) : stackHandlesPtr;
         
         try
         {
@@ -81,16 +91,10 @@ public partial class SafeChildProcessHandle
                 UnixHelpers.AllocNullTerminatedArray(envp, ref envpPtr);
             }
             
-            // Allocate and copy inherited handles if provided (and not detached)
-            if (!detached && options.HasInheritedHandlesBeenAccessed && options.InheritedHandles.Count > 0)
+            // Copy inherited handles if provided (the snapshot is empty when detached)
+            for (int i = 0; i < inheritedHandlesCount; i++)
             {
-                inheritedHandlesCount = options.InheritedHandles.Count;
-                inheritedHandlesPtr = (int*)NativeMemory.Alloc((nuint)inheritedHandlesCount, (nuint)sizeof(int));
-                
-                for (int i = 0; i < inheritedHandlesCount; i++)
-                {
-                    inheritedHandlesPtr[i] = (int)options.InheritedHandles[i].DangerousGetHandle();
-                }
+                inheritedHandlesPtr[i] = (int)inheritedHandles[i].DangerousGetHandle();
             }
 
             // Call native library to spawn process
@@ -110,7 +114,8 @@ public partial class SafeChildProcessHandle
                 options.CreateNewProcessGroup ? 1 : 0,
                 detached ? 1 : 0,
                 inheritedHandlesPtr,
-                inheritedHandlesCount);
+                inheritedHandlesCount,
+                options.TraceChildWithPtrace ? 1 : 0);
 
             if (result == -1)
             {
@@ -127,7 +132,10 @@ public partial class SafeChildProcessHandle
             UnixHelpers.FreePointer(workingDirPtr);
             UnixHelpers.FreeArray(envpPtr, envp?.Length ?? 0);
             UnixHelpers.FreeArray(argvPtr, argv.Length);
-            NativeMemory.Free(inheritedHandlesPtr);
+            if (useHeap)
+            {
+                NativeMemory.Free(inheritedHandlesPtr);
+            }
         }
     }
 
@@ -147,19 +155,22 @@ public partial class SafeChildProcessHandle
 
     private ProcessExitStatus WaitForExitCore()
     {
-        switch (wait_for_exit_and_reap(this, ProcessId, out int exitCode, out int rawSignal))
+        if (wait_for_exit(this, ProcessId) == -1)
         {
-            case -1:
-                int errno = Marshal.GetLastPInvokeError();
-                throw new Win32Exception(errno, $"wait_for_exit_and_reap() failed with (errno={errno})");
-            default:
-                return new(exitCode, false, rawSignal != 0 ? (PosixSignal)rawSignal : null);
+            int errno = Marshal.GetLastPInvokeError();
+            // ECHILD means that a concurrent waiter has already reaped the process (and cached its exit status).
+            if (errno != ECHILD)
+            {
+                throw new Win32Exception(errno, $"wait_for_exit() failed with (errno={errno})");
+            }
         }
+
+        return GetExitStatusOfExitedProcess(canceled: false);
     }
 
     private bool TryWaitForExitCore(int milliseconds, [NotNullWhen(true)] out ProcessExitStatus? exitStatus)
     {
-        switch (try_wait_for_exit(this, ProcessId, milliseconds, out int exitCode, out int rawSignal, out int hasTimedout))
+        switch (try_wait_for_exit(this, ProcessId, milliseconds))
         {
             case -1:
                 int errno = Marshal.GetLastPInvokeError();
@@ -168,21 +179,35 @@ public partial class SafeChildProcessHandle
                 exitStatus = null;
                 return false;
             default:
-                exitStatus = new(exitCode, false, rawSignal != 0 ? (PosixSignal)rawSignal : null);
+                exitStatus = GetExitStatusOfExitedProcess(canceled: false);
                 return true;
         }
     }
 
     private ProcessExitStatus WaitForExitOrKillOnTimeoutCore(int milliseconds)
     {
-        switch (wait_for_exit_or_kill_on_timeout(this, ProcessId, milliseconds, out int exitCode, out int rawSignal, out int hasTimedout))
+        if (wait_for_exit_or_kill_on_timeout(this, ProcessId, milliseconds, out int hasTimedout) == -1)
         {
-            case -1:
-                int errno = Marshal.GetLastPInvokeError();
+            int errno = Marshal.GetLastPInvokeError();
+            if (errno != ECHILD)
+            {
                 throw new Win32Exception(errno, $"wait_for_exit_or_kill_on_timeout() failed with (errno={errno})");
-            default:
-                return new(exitCode, hasTimedout == 1, rawSignal != 0 ? (PosixSignal)rawSignal : null);
+            }
         }
+
+        return GetExitStatusOfExitedProcess(canceled: hasTimedout == 1);
+    }
+
+    // The native wait functions don't reap the process, it's done exactly once by TryGetExitStatus.
+    private ProcessExitStatus GetExitStatusOfExitedProcess(bool canceled)
+    {
+        if (!TryGetExitStatus(canceled, out ProcessExitStatus? exitStatus))
+        {
+            int errno = Marshal.GetLastPInvokeError();
+            throw new Win32Exception(errno, $"try_get_exit_code() failed with (errno={errno})");
+        }
+
+        return exitStatus;
     }
 
     // After the code is moved to dotnet/runtime, it's going to use kqeue and epoll and the sockets thread to optimize perf and resources
@@ -205,7 +230,7 @@ public partial class SafeChildProcessHandle
 
             return await Task.Run(() =>
             {
-                switch (try_wait_for_exit_cancellable(this, ProcessId, (int)readHandle.DangerousGetHandle(), out int exitCode, out int rawSignal))
+                switch (try_wait_for_exit_cancellable(this, ProcessId, (int)readHandle.DangerousGetHandle()))
                 {
                     case -1:
                         int errno = Marshal.GetLastPInvokeError();
@@ -213,7 +238,7 @@ public partial class SafeChildProcessHandle
                     case 1: // canceled
                         throw new OperationCanceledException(cancellationToken);
                     default:
-                        return new ProcessExitStatus(exitCode, false, rawSignal != 0 ? (PosixSignal)rawSignal : null);
+                        return GetExitStatusOfExitedProcess(canceled: false);
                 }
             }, cancellationToken);
         }
@@ -238,7 +263,7 @@ public partial class SafeChildProcessHandle
 
             return await Task.Run(() =>
             {
-                switch (try_wait_for_exit_cancellable(this, ProcessId, (int)readHandle.DangerousGetHandle(), out int exitCode, out int rawSignal))
+                switch (try_wait_for_exit_cancellable(this, ProcessId, (int)readHandle.DangerousGetHandle()))
                 {
                     case -1:
                         int errno = Marshal.GetLastPInvokeError();
@@ -248,7 +273,7 @@ public partial class SafeChildProcessHandle
                         ProcessExitStatus status = WaitForExitCore();
                         return new ProcessExitStatus(status.ExitCode, wasKilled, status.Signal);
                     default:
-                        return new ProcessExitStatus(exitCode, false, rawSignal != 0 ? (PosixSignal)rawSignal : null);
+                        return GetExitStatusOfExitedProcess(canceled: false);
                 }
             }, cancellationToken);
         }
@@ -298,6 +323,100 @@ public partial class SafeChildProcessHandle
         throw new Win32Exception(errno, $"Failed to send signal {signal} (errno={errno})");
     }
 
+    private string? DumpCoreCore(PosixSignal signal)
+    {
+        if (!OperatingSystem.IsLinux())
+        {
+            throw new PlatformNotSupportedException("Dumping core on demand is supported only on Linux.");
+        }
+
+        // Once the process was reaped, its PID could have been reused by another process.
+        if (_exitStatus is not null)
+        {
+            throw new InvalidOperationException("The process has already been waited for.");
+        }
+
+        // /proc/<pid> is gone together with the process, so everything is read before sending the signal.
+        string? corePath = GetExpectedCorePath();
+
+        SendSignalCore(signal, entireProcessGroup: false);
+
+        return corePath;
+    }
+
+    private string? GetExpectedCorePath()
+    {
+        try
+        {
+            // "Max core file size        0                    unlimited            bytes"
+            foreach (string line in File.ReadLines($"/proc/{ProcessId}/limits"))
+            {
+                if (line.StartsWith("Max core file size", StringComparison.Ordinal))
+                {
+                    if (line.Split(' ', StringSplitOptions.RemoveEmptyEntries)[4] == "0")
+                    {
+                        return null;
+                    }
+                    break;
+                }
+            }
+
+            string pattern = File.ReadAllText("/proc/sys/kernel/core_pattern").TrimEnd('\n');
+            if (pattern.Length == 0 || pattern[0] == '|')
+            {
+                return null; // the core is piped to a handler, there is no file we could point to
+            }
+
+            StringBuilder path = new();
+            bool hasPid = false;
+            for (int i = 0; i < pattern.Length; i++)
+            {
+                if (pattern[i] != '%' || i == pattern.Length - 1)
+                {
+                    path.Append(pattern[i]);
+                    continue;
+                }
+
+                switch (pattern[++i])
+                {
+                    case '%':
+                        path.Append('%');
+                        break;
+                    case 'p' or 'P' or 'i' or 'I': // the main thread receives the signal, its TID is the PID
+                        path.Append(ProcessId);
+                        hasPid = true;
+                        break;
+                    case 'e':
+                        path.Append(File.ReadAllText($"/proc/{ProcessId}/comm").TrimEnd('\n'));
+                        break;
+                    case 'h':
+                        path.Append(Environment.MachineName);
+                        break;
+                    default:
+                        return null; // e.g. the time of the dump (%t), which we can't predict
+                }
+            }
+
+            if (!hasPid && File.ReadAllText("/proc/sys/kernel/core_uses_pid").Trim() == "1")
+            {
+                path.Append('.').Append(ProcessId);
+            }
+
+            string corePath = path.ToString();
+            if (Path.IsPathRooted(corePath))
+            {
+                return corePath;
+            }
+
+            string? workingDirectory = Directory.ResolveLinkTarget($"/proc/{ProcessId}/cwd", returnFinalTarget: false)?.FullName;
+            return workingDirectory is null ? null : Path.Combine(workingDirectory, corePath);
+        }
+        catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+        {
+            return null;
+        }
+    }
+
     private void ResumeCore()
     {
         // Resume a suspended process by sending SIGCONT
@@ -312,6 +431,324 @@ public partial class SafeChildProcessHandle
         throw new Win32Exception(errno, $"Failed to resume process (errno={errno})");
     }
 
+    private int WaitForRawStatusCore()
+    {
+        while (true)
+        {
+            bool hasStateChange = wait_for_state_change(ProcessId) == 0;
+            int errno = hasStateChange ? 0 : Marshal.GetLastPInvokeError();
+            if (!hasStateChange && errno != ECHILD)
+            {
+                throw new Win32Exception(errno, $"wait_for_state_change() failed with (errno={errno})");
+            }
+
+            // The exit is consumed under the same lock as in TryGetExitStatus, so the process is reaped exactly once.
+            lock (_exitStatusLock)
+            {
+                // Once the process was reaped, its raw status is gone and its PID could have been reused by another process.
+                if (_exitStatus is not null)
+                {
+                    throw new InvalidOperationException("The process has already been waited for.");
+                }
+                else if (!hasStateChange)
+                {
+                    throw new Win32Exception(errno, $"wait_for_state_change() failed with (errno={errno})");
+                }
+
+                switch (try_get_raw_status(ProcessId, out int status, out int exitCode, out int rawSignal))
+                {
+                    case -1:
+                        errno = Marshal.GetLastPInvokeError();
+                        throw new Win32Exception(errno, $"try_get_raw_status() failed with (errno={errno})");
+                    case 1: // exited and reaped
+                        SetExitStatus(new(exitCode, false, rawSignal != 0 ? (PosixSignal)rawSignal : null));
+                        return status;
+                    case 0: // stopped or continued
+                        return status;
+                    default: // consumed by a concurrent caller, wait for the next state change
+                        break;
+                }
+            }
+        }
+    }
+
+    private bool ResumeAfterDebuggerAttachCore(int milliseconds)
+        => throw new PlatformNotSupportedException("Waiting for a debugger to attach is supported only on Windows.");
+
+    private bool CloseMainWindowCore()
+        => throw new PlatformNotSupportedException("Closing the main window is supported only on Windows.");
+
+    private bool RequestGracefulExitCore(bool entireProcessGroup)
+    {
+        SendSignalCore(PosixSignal.SIGTERM, entireProcessGroup);
+        return true;
+    }
+
+    private void DetachTracerCore()
+    {
+        if (!OperatingSystem.IsLinux())
+        {
+            throw new PlatformNotSupportedException("Tracing the child process with ptrace is supported only on Linux.");
+        }
+
+        if (detach_tracer(ProcessId) == -1)
+        {
+            int errno = Marshal.GetLastPInvokeError();
+            throw new Win32Exception(errno, $"detach_tracer() failed with (errno={errno})");
+        }
+    }
+
+    private unsafe string? GetExecutablePathCore()
+    {
+        // Once the process was reaped, its PID could have been reused by another process.
+        if (_exitStatus is not null)
+        {
+            return null;
+        }
+
+        if (OperatingSystem.IsMacOS())
+        {
+            const int PROC_PIDPATHINFO_MAXSIZE = 4096;
+            byte* buffer = stackalloc byte[PROC_PIDPATHINFO_MAXSIZE];
+            int length = proc_pidpath(ProcessId, buffer, PROC_PIDPATHINFO_MAXSIZE);
+
+            return length > 0 ? Marshal.PtrToStringUTF8((IntPtr)buffer, length) : null;
+        }
+
+        try
+        {
+            return File.ResolveLinkTarget($"/proc/{ProcessId}/exe", returnFinalTarget: false)?.FullName;
+        }
+        catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+        {
+            // The process has exited or we lack the permissions to inspect it.
+            return null;
+        }
+    }
+
+    // The process group ID of a process started with CreateNewProcessGroup=true is equal to its PID.
+    private unsafe List<int> GetProcessGroupMembersCore()
+    {
+        List<int> members = new();
+
+        if (OperatingSystem.IsMacOS())
+        {
+            const uint PROC_PGRP_ONLY = 2;
+            int[] buffer = new int[256];
+            while (true)
+            {
+                int bytes;
+                fixed (int* bufferPtr = buffer)
+                {
+                    bytes = proc_listpids(PROC_PGRP_ONLY, (uint)ProcessId, bufferPtr, buffer.Length * sizeof(int));
+                }
+
+                if (bytes < 0)
+                {
+                    int errno = Marshal.GetLastPInvokeError();
+                    throw new Win32Exception(errno, $"Failed to list processes of the process group (errno={errno})");
+                }
+
+                int count = bytes / sizeof(int);
+                if (count < buffer.Length)
+                {
+                    for (int i = 0; i < count; i++)
+                    {
+                        if (buffer[i] != 0)
+                        {
+                            members.Add(buffer[i]);
+                        }
+                    }
+                    return members;
+                }
+
+                // The buffer might have been too small, try again with a larger one.
+                buffer = new int[buffer.Length * 2];
+            }
+        }
+
+        foreach (string directory in Directory.EnumerateDirectories("/proc"))
+        {
+            if (!int.TryParse(Path.GetFileName(directory.AsSpan()), out int pid))
+            {
+                continue;
+            }
+
+            string stat;
+            try
+            {
+                stat = File.ReadAllText($"{directory}/stat");
+            }
+            catch (Exception ex) when (ex is IOException or UnauthorizedAccessException)
+            {
+                continue; // The process has exited in the meantime.
+            }
+
+            // The process name (2nd field) can contain spaces and parentheses, so we parse the fields after the last ')'.
+            // They are: state ppid pgrp.
+            string[] fields = stat.Substring(stat.LastIndexOf(')') + 1).Split(' ', 4, StringSplitOptions.RemoveEmptyEntries);
+            // Zombies have already been terminated, they are just waiting to be reaped by their parent.
+            if (fields.Length >= 3 && fields[0] != "Z" && int.TryParse(fields[2], out int processGroupId) && processGroupId == ProcessId)
+            {
+                members.Add(pid);
+            }
+        }
+
+        return members;
+    }
+
+    // The members are not our children, so they can be neither opened nor reaped, only observed (via pidfd or kqueue).
+    private static bool WaitForProcessGroupMemberExitCore(int processId, int milliseconds)
+    {
+        switch (wait_for_process_exit(processId, milliseconds))
+        {
+            case -1:
+                int errno = Marshal.GetLastPInvokeError();
+                throw new Win32Exception(errno, $"wait_for_process_exit() failed with (errno={errno})");
+            case 1: // timeout
+                return false;
+            default:
+                return true;
+        }
+    }
+
+    // Unix has no handle model, the closest equivalent are file descriptors.
+    private int GetHandleCountCore() => -1;
+
+    private ProcessPriority GetPriorityCore()
+    {
+        // Once the process was reaped, its PID could have been reused by another process.
+        if (_exitStatus is not null)
+        {
+            throw new InvalidOperationException("The process has already been waited for.");
+        }
+
+        if (get_priority(ProcessId, out int nice) == -1)
+        {
+            int errno = Marshal.GetLastPInvokeError();
+            throw new Win32Exception(errno, $"Failed to get priority of the process (errno={errno})");
+        }
+
+        return nice switch
+        {
+            <= -15 => ProcessPriority.RealTime,
+            <= -10 => ProcessPriority.High,
+            < 0 => ProcessPriority.AboveNormal,
+            0 => ProcessPriority.Normal,
+            <= 10 => ProcessPriority.BelowNormal,
+            _ => ProcessPriority.Idle,
+        };
+    }
+
+    private void SetPriorityCore(ProcessPriority priority)
+    {
+        // Once the process was reaped, its PID could have been reused by another process.
+        if (_exitStatus is not null)
+        {
+            throw new InvalidOperationException("The process has already been waited for.");
+        }
+
+        int nice = priority switch
+        {
+            ProcessPriority.Idle => 19,
+            ProcessPriority.BelowNormal => 10,
+            ProcessPriority.Normal => 0,
+            ProcessPriority.AboveNormal => -6,
+            ProcessPriority.High => -11,
+            _ => -19,
+        };
+
+        if (set_priority(ProcessId, nice) == -1)
+        {
+            int errno = Marshal.GetLastPInvokeError();
+            throw new Win32Exception(errno, $"Failed to set priority of the process (errno={errno})");
+        }
+    }
+
+    private List<string> GetLoadedModulesCore()
+    {
+        if (!OperatingSystem.IsLinux())
+        {
+            throw new PlatformNotSupportedException("Listing the loaded modules is supported only on Linux.");
+        }
+
+        // Once the process was reaped, its PID could have been reused by another process.
+        if (_exitStatus is not null)
+        {
+            throw new InvalidOperationException("The process has already been waited for.");
+        }
+
+        List<string> modules = new();
+        HashSet<string> seen = new(StringComparer.Ordinal);
+
+        // "7f2c1a428000-7f2c1a5bd000 r-xp 00028000 08:01 1054920                    /usr/lib/x86_64-linux-gnu/libc.so.6"
+        foreach (string line in File.ReadLines($"/proc/{ProcessId}/maps"))
+        {
+            ReadOnlySpan<char> remaining = line;
+            ReadOnlySpan<char> permissions = default, inode = default;
+            for (int field = 0; field < 5; field++)
+            {
+                remaining = remaining.TrimStart(' ');
+                int end = remaining.IndexOf(' ');
+                if (end < 0)
+                {
+                    remaining = default;
+                    break;
+                }
+
+                if (field == 1)
+                {
+                    permissions = remaining.Slice(0, end);
+                }
+                else if (field == 4)
+                {
+                    inode = remaining.Slice(0, end);
+                }
+                remaining = remaining.Slice(end);
+            }
+
+            // Anonymous mappings have inode 0, pseudo ones (like [vdso]) don't start with a slash.
+            ReadOnlySpan<char> path = remaining.Trim(' ');
+            if (permissions.Length < 3 || permissions[2] != 'x' || inode.SequenceEqual("0") || path.IsEmpty || path[0] != '/')
+            {
+                continue;
+            }
+
+            string module = path.ToString();
+            if (seen.Add(module))
+            {
+                modules.Add(module);
+            }
+        }
+
+        return modules;
+    }
+
+    private TimeSpan GetTotalProcessorTimeCore()
+    {
+        // Once the process was reaped, its PID could have been reused by another process.
+        if (_exitStatus is not null)
+        {
+            throw new InvalidOperationException("The process has already been waited for.");
+        }
+
+        if (get_cpu_time(ProcessId, out ulong cpuTimeNs) == -1)
+        {
+            int errno = Marshal.GetLastPInvokeError();
+            throw new Win32Exception(errno, $"Failed to get CPU time of the process (errno={errno})");
+        }
+
+        return TimeSpan.FromTicks((long)(cpuTimeNs / 100));
+    }
+
+    private const int ECHILD = 10; // No child processes
+
+    [LibraryImport("libproc", SetLastError = true)]
+    private static unsafe partial int proc_pidpath(int pid, byte* buffer, uint buffersize);
+
+    [LibraryImport("libproc", SetLastError = true)]
+    private static unsafe partial int proc_listpids(uint type, uint typeinfo, int* buffer, int buffersize);
+
     [LibraryImport("libc", SetLastError = true)]
     private static partial int close(int fd);
 
@@ -332,29 +769,51 @@ public partial class SafeChildProcessHandle
         int create_new_process_group,
         int detached,
         int* inherited_handles,
-        int inherited_handles_count);
+        int inherited_handles_count,
+        int trace_with_ptrace);
 
     [LibraryImport("pal_process", SetLastError = true)]
     private static partial int send_signal(int pidfd, int pid, PosixSignal managed_signal);
 
     [LibraryImport("pal_process", SetLastError = true)]
-    private static partial int wait_for_exit_and_reap(SafeChildProcessHandle pidfd, int pid, out int exitCode, out int signal);
+    private static partial int wait_for_exit(SafeChildProcessHandle pidfd, int pid);
 
     [LibraryImport("pal_process", SetLastError = true)]
-    private static partial int try_wait_for_exit(SafeChildProcessHandle pidfd, int pid, int timeout_ms, out int exitCode, out int signal, out int hasTimedout);
+    private static partial int try_wait_for_exit(SafeChildProcessHandle pidfd, int pid, int timeout_ms);
 
     [LibraryImport("pal_process", SetLastError = true)]
-    private static partial int try_wait_for_exit_cancellable(SafeChildProcessHandle pidfd, int pid, int cancelPipeFd, out int exitCode, out int signal);
+    private static partial int try_wait_for_exit_cancellable(SafeChildProcessHandle pidfd, int pid, int cancelPipeFd);
 
     [LibraryImport("pal_process", SetLastError = true)]
-    private static partial int wait_for_exit_or_kill_on_timeout(SafeChildProcessHandle pidfd, int pid, int timeout_ms, out int exitCode, out int signal, out int hasTimedout);
+    private static partial int wait_for_exit_or_kill_on_timeout(SafeChildProcessHandle pidfd, int pid, int timeout_ms, out int hasTimedout);
 
     [LibraryImport("pal_process", SetLastError = true)]
     private static partial int try_get_exit_code(SafeChildProcessHandle pidfd, int pid, out int exitCode, out int signal);
 
+    [LibraryImport("pal_process", SetLastError = true)]
+    private static partial int wait_for_state_change(int pid);
+
+    [LibraryImport("pal_process", SetLastError = true)]
+    private static partial int try_get_raw_status(int pid, out int status, out int exitCode, out int signal);
+
+    [LibraryImport("pal_process", SetLastError = true)]
+    private static partial int detach_tracer(int pid);
+
+    [LibraryImport("pal_process", SetLastError = true)]
+    private static partial int get_priority(int pid, out int priority);
+
+    [LibraryImport("pal_process", SetLastError = true)]
+    private static partial int set_priority(int pid, int priority);
+
     [LibraryImport("pal_process", SetLastError = true)]
     private static partial int open_process(int pid, out int out_pidfd);
 
+    [LibraryImport("pal_process", SetLastError = true)]
+    private static partial int get_cpu_time(int pid, out ulong out_cpu_time_ns);
+
+    [LibraryImport("pal_process", SetLastError = true)]
+    private static partial int wait_for_process_exit(int pid, int timeout_ms);
+
     private static SafeChildProcessHandle OpenCore(int processId)
     {
         int result = open_process(processId, out int pidfd);
@@ -1,4 +1,6 @@
 using System;
+using System.Buffers;
+using System.Collections.Generic;
 using System.ComponentModel;
 using System.Diagnostics.CodeAnalysis;
 using System.Runtime.CompilerServices;
@@ -83,18 +85,15 @@ public partial class SafeChildProcessHandle
 
     // Linux doesn't have a corresponding sys-call just to get exit code of a process by its handle.
     // That is why it's Windows-specific helper.
-    internal int GetExitCode()
+    // Every wait ends here, so the exit status is cached (and the exit time recorded) exactly once and all the waiters get the same instance.
+    private ProcessExitStatus GetExitStatusOfExitedProcess(bool canceled)
     {
-        if (!Interop.Kernel32.GetExitCodeProcess(this, out int exitCode))
+        if (!TryGetExitStatus(canceled, out ProcessExitStatus? exitStatus))
         {
             throw new InvalidOperationException("Parent process should alway be able to get the exit code.");
         }
-        else if (exitCode == Interop.Kernel32.HandleOptions.STILL_ACTIVE)
-        {
-            throw new InvalidOperationException("Process has not exited yet.");
-        }
 
-        return exitCode;
+        return exitStatus;
     }
 
     private bool TryGetExitCodeCore(out int exitCode, out PosixSignal? signal)
@@ -105,12 +104,17 @@ public partial class SafeChildProcessHandle
             && exitCode != Interop.Kernel32.HandleOptions.STILL_ACTIVE;
     }
 
-    private static unsafe SafeChildProcessHandle StartCore(ProcessStartOptions options, SafeFileHandle inputHandle, SafeFileHandle outputHandle, SafeFileHandle errorHandle, bool createSuspended, bool detached)
+    private static unsafe SafeChildProcessHandle StartCore(ProcessStartOptions options, SafeHandle[] inheritedHandles, SafeFileHandle inputHandle, SafeFileHandle outputHandle, SafeFileHandle errorHandle, bool createSuspended, bool detached)
     {
         ValueStringBuilder applicationName = new(stackalloc char[256]);
         ValueStringBuilder commandLine = new(stackalloc char[256]);
         ProcessUtils.BuildArgs(options, ref applicationName, ref commandLine);
 
+        if (options.LaunchAuditCallback is not null)
+        {
+            AuditLaunch(options, applicationName.AsSpan().ToString(), [.. options.Arguments]);
+        }
+
         Interop.Kernel32.STARTUPINFOEX startupInfoEx = default;
         Interop.Kernel32.PROCESS_INFORMATION processInfo = default;
         Interop.Kernel32.SECURITY_ATTRIBUTES unused_SecAttrs = default;
@@ -134,12 +138,15 @@ public partial class SafeChildProcessHandle
                 ? duplicatedInput
                 : Duplicate(errorHandle, currentProcHandle));
 
-        // Calculate total handle count: stdio handles (max 3) + user-provided inherited handles
-        int maxHandleCount = 3 + (options.HasInheritedHandlesBeenAccessed ? options.InheritedHandles.Count : 0);
-        
-        // Allocate handles array on heap (simpler for .NET Framework compatibility)
-        IntPtr heapHandlesPtr = Marshal.AllocHGlobal(maxHandleCount * sizeof(IntPtr));
-        IntPtr* handlesToInherit = (IntPtr*)heapHandlesPtr;
+        // Calculate total handle count: stdio handles (max 3) + user-provided inherited handles.
+        // The stdio handles MUST be included, otherwise the buffer could be under-sized.
+        int maxHandleCount = StdioHandleCount + inheritedHandles.Length;
+
+        // Small lists are stack allocated, the rest goes to the heap to avoid stack overflow.
+        bool useHeap = maxHandleCount > MaxStackAllocatedHandleCount;
+        IntPtr heapHandlesPtr = useHeap ? Marshal.AllocHGlobal(maxHandleCount * sizeof(IntPtr)) : IntPtr.Zero;
+        IntPtr* stackHandlesPtr = stackalloc IntPtr[useHeap ? 0 : maxHandleCount];
+        IntPtr* handlesToInherit = useHeap ? (IntPtr*)heapHandlesPtr : stackHandlesPtr;
         IntPtr processGroupJobHandle = IntPtr.Zero;
 
         try
@@ -150,7 +157,7 @@ public partial class SafeChildProcessHandle
             IntPtr outputPtr = duplicatedOutput.DangerousGetHandle();
             IntPtr errorPtr = duplicatedError.DangerousGetHandle();
 
-            PrepareHandleAllowList(options, handlesToInherit, ref handleCount, inputPtr, outputPtr, errorPtr);
+            PrepareHandleAllowList(inheritedHandles, handlesToInherit, ref handleCount, inputPtr, outputPtr, errorPtr);
 
             // Create a job object if CreateNewProcessGroup is requested or if detached
             // This must happen before starting the process to ensure atomicity
@@ -235,13 +242,14 @@ public partial class SafeChildProcessHandle
             if (detached) creationFlags |= Interop.Advapi32.StartupInfoOptions.DETACHED_PROCESS;
 
             string? environmentBlock = null;
-            if (options.HasEnvironmentBeenAccessed)
+            IDictionary<string, string?>? environment = options.GetEffectiveEnvironment();
+            if (environment is not null)
             {
                 creationFlags |= Interop.Advapi32.StartupInfoOptions.CREATE_UNICODE_ENVIRONMENT;
-                environmentBlock = ProcessUtils.GetEnvironmentVariablesBlock(options.Environment);
+                environmentBlock = ProcessUtils.GetEnvironmentVariablesBlock(environment);
             }
 
-            string? workingDirectory = options.WorkingDirectory;
+            string? workingDirectory = options.GetEffectiveWorkingDirectory(applicationName.AsSpan());
             int errorCode = 0;
 
             fixed (char* environmentBlockPtr = environmentBlock)
@@ -303,7 +311,7 @@ public partial class SafeChildProcessHandle
         }
         finally
         {
-            // Free heap-allocated handles array
+            // Free heap-allocated handles array (no-op when it was stack allocated)
             Marshal.FreeHGlobal(heapHandlesPtr);
             
             if (attributeListBuffer != IntPtr.Zero)
@@ -338,7 +346,7 @@ public partial class SafeChildProcessHandle
         }
     }
 
-    private static unsafe void PrepareHandleAllowList(ProcessStartOptions options, IntPtr* handlesToInherit, ref int handleCount, IntPtr inputPtr, IntPtr outputPtr, IntPtr errorPtr)
+    private static unsafe void PrepareHandleAllowList(SafeHandle[] inheritedHandles, IntPtr* handlesToInherit, ref int handleCount, IntPtr inputPtr, IntPtr outputPtr, IntPtr errorPtr)
     {
         handlesToInherit[handleCount++] = inputPtr;
         if (outputPtr != inputPtr)
@@ -347,45 +355,42 @@ public partial class SafeChildProcessHandle
             handlesToInherit[handleCount++] = errorPtr;
 
         // Add user-provided inherited handles, avoiding duplicates
-        if (options.HasInheritedHandlesBeenAccessed)
+        foreach (SafeHandle handle in inheritedHandles)
         {
-            foreach (SafeHandle handle in options.InheritedHandles)
-            {
-                IntPtr handlePtr = handle.DangerousGetHandle();
+            IntPtr handlePtr = handle.DangerousGetHandle();
 
-                // Check if this handle is already in the list
-                bool isDuplicate = false;
-                for (int i = 0; i < handleCount; i++)
+            // Check if this handle is already in the list
+            bool isDuplicate = false;
+            for (int i = 0; i < handleCount; i++)
+            {
+                if (handlesToInherit[i] == handlePtr)
                 {
-                    if (handlesToInherit[i] == handlePtr)
-                    {
-                        isDuplicate = true;
-                        break;
-                    }
+                    isDuplicate = true;
+                    break;
                 }
+            }
 
-                if (!isDuplicate)
+            if (!isDuplicate)
+            {
+                // Ensure the handle has inheritance enabled
+                if (!Interop.Kernel32.GetHandleInformation(handlePtr, out int flags))
                 {
-                    // Ensure the handle has inheritance enabled
-                    if (!Interop.Kernel32.GetHandleInformation(handlePtr, out int flags))
-                    {
-                        throw new Win32Exception(Marshal.GetLastPInvokeError(), "Failed to get handle information");
-                    }
+                    throw new Win32Exception(Marshal.GetLastPInvokeError(), "Failed to get handle information");
+                }
 
-                    // If inheritance is not enabled, enable it
-                    if ((flags & Interop.Kernel32.HandleOptions.HANDLE_FLAG_INHERIT) == 0)
+                // If inheritance is not enabled, enable it
+                if ((flags & Interop.Kernel32.HandleOptions.HANDLE_FLAG_INHERIT) == 0)
+                {
+                    if (!Interop.Kernel32.SetHandleInformation(
+                        handlePtr,
+                        Interop.Kernel32.HandleOptions.HANDLE_FLAG_INHERIT,
+                        Interop.Kernel32.HandleOptions.HANDLE_FLAG_INHERIT))
                     {
-                        if (!Interop.Kernel32.SetHandleInformation(
-                            handlePtr,
-                            Interop.Kernel32.HandleOptions.HANDLE_FLAG_INHERIT,
-                            Interop.Kernel32.HandleOptions.HANDLE_FLAG_INHERIT))
-                        {
-                            throw new Win32Exception(Marshal.GetLastPInvokeError(), "Failed to set handle inheritance");
-                        }
+                        throw new Win32Exception(Marshal.GetLastPInvokeError(), "Failed to set handle inheritance");
                     }
-
-                    handlesToInherit[handleCount++] = handlePtr;
                 }
+
+                handlesToInherit[handleCount++] = handlePtr;
             }
         }
     }
@@ -395,7 +400,7 @@ public partial class SafeChildProcessHandle
         using Interop.Kernel32.ProcessWaitHandle processWaitHandle = new(this);
         processWaitHandle.WaitOne(Timeout.Infinite);
 
-        return new(GetExitCode(), false);
+        return GetExitStatusOfExitedProcess(canceled: false);
     }
 
     private bool TryWaitForExitCore(int milliseconds, [NotNullWhen(true)] out ProcessExitStatus? exitStatus)
@@ -407,7 +412,7 @@ public partial class SafeChildProcessHandle
             return false;
         }
 
-        exitStatus = new(GetExitCode(), false);
+        exitStatus = GetExitStatusOfExitedProcess(canceled: false);
         return true;
     }
 
@@ -420,7 +425,7 @@ public partial class SafeChildProcessHandle
             wasKilledOnTimeout = KillCore(throwOnError: false);
         }
 
-        return new(GetExitCode(), wasKilledOnTimeout);
+        return GetExitStatusOfExitedProcess(canceled: wasKilledOnTimeout);
     }
 
     private async Task<ProcessExitStatus> WaitForExitAsyncCore(CancellationToken cancellationToken)
@@ -460,7 +465,7 @@ public partial class SafeChildProcessHandle
             registeredWaitHandle?.Unregister(null);
         }
 
-        return new(GetExitCode(), false);
+        return GetExitStatusOfExitedProcess(canceled: false);
     }
 
     private async Task<ProcessExitStatus> WaitForExitOrKillOnCancellationAsyncCore(CancellationToken cancellationToken)
@@ -502,7 +507,7 @@ public partial class SafeChildProcessHandle
             registeredWaitHandle?.Unregister(null);
         }
 
-        return new(GetExitCode(), wasKilledBox.Value);
+        return GetExitStatusOfExitedProcess(canceled: wasKilledBox.Value);
     }
 
     /// <summary>
@@ -547,6 +552,124 @@ public partial class SafeChildProcessHandle
         }
     }
 
+    private int WaitForRawStatusCore()
+        => throw new PlatformNotSupportedException("The raw wait status is available only on Unix.");
+
+    private void DetachTracerCore()
+        => throw new PlatformNotSupportedException("Tracing the child process with ptrace is supported only on Linux.");
+
+    private string? DumpCoreCore(PosixSignal signal)
+        => throw new PlatformNotSupportedException("Dumping core on demand is supported only on Linux.");
+
+    private List<string> GetLoadedModulesCore()
+        => throw new PlatformNotSupportedException("Listing the loaded modules is supported only on Linux.");
+
+    private unsafe bool CloseMainWindowCore()
+    {
+        MainWindowSearch search = new(ProcessId);
+        GCHandle searchHandle = GCHandle.Alloc(search);
+        try
+        {
+            Interop.User32.EnumWindows(&FindMainWindows, GCHandle.ToIntPtr(searchHandle));
+        }
+        finally
+        {
+            searchHandle.Free();
+        }
+
+        if (search.Windows.Count == 0)
+        {
+            return false;
+        }
+
+        int error = 0;
+        bool posted = false;
+        foreach (IntPtr window in search.Windows)
+        {
+            // PostMessage doesn't wait for the window to process the message, so a hung app can't block the caller.
+            if (Interop.User32.PostMessage(window, Interop.User32.WM_CLOSE, IntPtr.Zero, IntPtr.Zero) != Interop.BOOL.FALSE)
+            {
+                posted = true;
+            }
+            else
+            {
+                error = Marshal.GetLastPInvokeError();
+            }
+        }
+
+        // For example, UIPI blocks messages to windows of elevated processes.
+        return posted ? true : throw new Win32Exception(error, "Failed to post WM_CLOSE to the main window of the process");
+    }
+
+    private bool RequestGracefulExitCore(bool entireProcessGroup)
+    {
+        bool requested = CloseMainWindowCore();
+        if (entireProcessGroup)
+        {
+            // CTRL_C_EVENT is ignored by processes started in a new process group, CTRL_BREAK_EVENT is not.
+            SendSignalCore(PosixSignal.SIGQUIT, entireProcessGroup);
+            requested = true;
+        }
+        return requested;
+    }
+
+    // Visible top-level windows without an owner, the same ones System.Diagnostics.Process considers main windows.
+    [UnmanagedCallersOnly]
+    private static unsafe Interop.BOOL FindMainWindows(IntPtr window, IntPtr extraData)
+    {
+        MainWindowSearch search = (MainWindowSearch)GCHandle.FromIntPtr(extraData).Target!;
+
+        int processId;
+        Interop.User32.GetWindowThreadProcessId(window, &processId);
+        if (processId == search.ProcessId
+            && Interop.User32.GetWindow(window, Interop.User32.GW_OWNER) == IntPtr.Zero
+            && Interop.User32.IsWindowVisible(window) != Interop.BOOL.FALSE)
+        {
+            search.Windows.Add(window);
+        }
+
+        return Interop.BOOL.TRUE; // continue the enumeration
+    }
+
+    private sealed class MainWindowSearch(int processId)
+    {
+        internal int ProcessId { get; } = processId;
+
+        internal List<IntPtr> Windows { get; } = new();
+    }
+
+    private bool ResumeAfterDebuggerAttachCore(int milliseconds)
+    {
+        if (_threadHandle == IntPtr.Zero)
+        {
+            throw new InvalidOperationException("Cannot resume a process that was not started with StartSuspended.");
+        }
+
+        // There is no notification for a debugger attaching to another process, so we poll.
+        const int PollIntervalMilliseconds = 50;
+        TimeoutHelper timeoutHelper = TimeoutHelper.Start(milliseconds == Timeout.Infinite ? null : TimeSpan.FromMilliseconds(milliseconds));
+        bool debuggerPresent;
+        while (true)
+        {
+            if (!Interop.Kernel32.CheckRemoteDebuggerPresent(this, out debuggerPresent))
+            {
+                int error = Marshal.GetLastPInvokeError();
+                throw new Win32Exception(error, "Failed to check if a debugger is attached");
+            }
+
+            if (debuggerPresent || timeoutHelper.HasExpired)
+            {
+                break;
+            }
+
+            Thread.Sleep(timeoutHelper.CanExpire ? Math.Min(PollIntervalMilliseconds, timeoutHelper.GetRemainingMilliseconds()) : PollIntervalMilliseconds);
+        }
+
+        ResumeCore();
+
+        return debuggerPresent;
+    }
+
     private void SendSignalCore(PosixSignal signal, bool entireProcessGroup)
     {
         // SIGKILL is handled by calling KillCore directly
@@ -588,6 +711,165 @@ public partial class SafeChildProcessHandle
         }
     }
 
+    private unsafe string? GetExecutablePathCore()
+    {
+        // Process image paths are not limited to MAX_PATH, so we grow the buffer up to the maximum extended path length.
+        const int MaxExtendedPathLength = 32767;
+        uint bufferSize = 260;
+
+        while (true)
+        {
+            char[] buffer = ArrayPool<char>.Shared.Rent((int)bufferSize);
+            try
+            {
+                uint length = (uint)buffer.Length;
+                fixed (char* bufferPtr = buffer)
+                {
+                    if (Interop.Kernel32.QueryFullProcessImageName(this, 0, bufferPtr, ref length))
+                    {
+                        return new string(buffer, 0, (int)length);
+                    }
+                }
+
+                int error = Marshal.GetLastPInvokeError();
+                if (error != Interop.Errors.ERROR_INSUFFICIENT_BUFFER || buffer.Length >= MaxExtendedPathLength)
+                {
+                    // ERROR_ACCESS_DENIED and friends: the handle lacks PROCESS_QUERY_LIMITED_INFORMATION.
+                    return null;
+                }
+
+                bufferSize = (uint)Math.Min(buffer.Length * 2, MaxExtendedPathLength);
+            }
+            finally
+            {
+                ArrayPool<char>.Shared.Return(buffer);
+            }
+        }
+    }
+
+    private unsafe List<int> GetProcessGroupMembersCore()
+    {
+        if (_processGroupJobHandle == IntPtr.Zero)
+        {
+            throw new InvalidOperationException("Cannot enumerate the process group because the process was not started with CreateNewProcessGroup=true.");
+        }
+
+        // JOBOBJECT_BASIC_PROCESS_ID_LIST: two DWORD counters followed by an array of ULONG_PTR process IDs.
+        const int HeaderSize = 2 * sizeof(uint);
+        int capacity = 64;
+        while (true)
+        {
+            byte[] buffer = new byte[HeaderSize + capacity * sizeof(nuint)];
+            fixed (byte* bufferPtr = buffer)
+            {
+                if (!Interop.Kernel32.QueryInformationJobObject(_processGroupJobHandle, Interop.Kernel32.JOBOBJECTINFOCLASS.JobObjectBasicProcessIdList,
+                    bufferPtr, (uint)buffer.Length, IntPtr.Zero))
+                {
+                    int error = Marshal.GetLastPInvokeError();
+                    if (error != Interop.Errors.ERROR_MORE_DATA)
+                    {
+                        throw new Win32Exception(error, "Failed to list processes of the job object");
+                    }
+                }
+
+                uint assignedCount = ((uint*)bufferPtr)[0];
+                uint listedCount = ((uint*)bufferPtr)[1];
+                if (listedCount < assignedCount)
+                {
+                    // The buffer is too small, or more processes have been assigned in the meantime.
+                    capacity = (int)assignedCount * 2;
+                    continue;
+                }
+
+                List<int> members = new((int)listedCount);
+                nuint* processIds = (nuint*)(bufferPtr + HeaderSize);
+                for (int i = 0; i < listedCount; i++)
+                {
+                    members.Add((int)processIds[i]);
+                }
+                return members;
+            }
+        }
+    }
+
+    private static bool WaitForProcessGroupMemberExitCore(int processId, int milliseconds)
+    {
+        IntPtr handle = Interop.Kernel32.OpenProcess(Interop.Advapi32.ProcessOptions.SYNCHRONIZE, bInheritHandle: false, processId);
+        if (handle == IntPtr.Zero)
+        {
+            // The process has exited in the meantime (or can't be waited for), the next enumeration of the job tells.
+            return true;
+        }
+
+        using SafeChildProcessHandle memberHandle = new(handle, processId, ownsHandle: true);
+        using Interop.Kernel32.ProcessWaitHandle processWaitHandle = new(memberHandle);
+        return processWaitHandle.WaitOne(milliseconds);
+    }
+
+    private TimeSpan GetTotalProcessorTimeCore()
+    {
+        if (!Interop.Kernel32.GetProcessTimes(this, out _, out _, out long kernelTime, out long userTime))
+        {
+            throw new Win32Exception(Marshal.GetLastPInvokeError(), "Failed to get CPU time of the process");
+        }
+
+        // Both times are expressed in 100-nanosecond units, which is exactly what a TimeSpan tick is.
+        return TimeSpan.FromTicks(kernelTime + userTime);
+    }
+
+    private int GetHandleCountCore()
+    {
+        // The handles of an exited process are closed by the OS, the count would be meaningless.
+        if (TryGetExitStatus(canceled: false, out _))
+        {
+            return -1;
+        }
+
+        if (!Interop.Kernel32.GetProcessHandleCount(this, out uint handleCount))
+        {
+            throw new Win32Exception(Marshal.GetLastPInvokeError(), "Failed to get handle count of the process");
+        }
+
+        return (int)handleCount;
+    }
+
+    private ProcessPriority GetPriorityCore()
+    {
+        int priorityClass = Interop.Kernel32.GetPriorityClass(this);
+        if (priorityClass == 0)
+        {
+            throw new Win32Exception(Marshal.GetLastPInvokeError(), "Failed to get priority class of the process");
+        }
+
+        return priorityClass switch
+        {
+            Interop.Advapi32.PriorityClass.IDLE_PRIORITY_CLASS => ProcessPriority.Idle,
+            Interop.Advapi32.PriorityClass.BELOW_NORMAL_PRIORITY_CLASS => ProcessPriority.BelowNormal,
+            Interop.Advapi32.PriorityClass.ABOVE_NORMAL_PRIORITY_CLASS => ProcessPriority.AboveNormal,
+            Interop.Advapi32.PriorityClass.HIGH_PRIORITY_CLASS => ProcessPriority.High,
+            Interop.Advapi32.PriorityClass.REALTIME_PRIORITY_CLASS => ProcessPriority.RealTime,
+            _ => ProcessPriority.Normal,
+        };
+    }
+
+    private void SetPriorityCore(ProcessPriority priority)
+    {
+        int priorityClass = priority switch
+        {
+            ProcessPriority.Idle => Interop.Advapi32.PriorityClass.IDLE_PRIORITY_CLASS,
+            ProcessPriority.BelowNormal => Interop.Advapi32.PriorityClass.BELOW_NORMAL_PRIORITY_CLASS,
+            ProcessPriority.Normal => Interop.Advapi32.PriorityClass.NORMAL_PRIORITY_CLASS,
+            ProcessPriority.AboveNormal => Interop.Advapi32.PriorityClass.ABOVE_NORMAL_PRIORITY_CLASS,
+            ProcessPriority.High => Interop.Advapi32.PriorityClass.HIGH_PRIORITY_CLASS,
+            _ => Interop.Advapi32.PriorityClass.REALTIME_PRIORITY_CLASS,
+        };
+
+        if (!Interop.Kernel32.SetPriorityClass(this, priorityClass))
+        {
+            throw new Win32Exception(Marshal.GetLastPInvokeError(), "Failed to set priority class of the process");
+        }
+    }
+
     private static SafeChildProcessHandle OpenCore(int processId)
     {
         // We use PROCESS_TERMINATE because the name "SafeChildProcessHandle" indicates that it's a child process,
@@ -1,4 +1,7 @@
 using System;
+using System.Collections.Generic;
+using System.ComponentModel;
+using System.Diagnostics;
 using System.Diagnostics.CodeAnalysis;
 using System.IO;
 using System.Runtime.InteropServices;
@@ -16,6 +19,29 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
 {
     internal static readonly SafeChildProcessHandle InvalidHandle = new();
 
+    // On Unix, a process can be reaped only once. The first caller that observes the exit
+    // caches the status, so all concurrent and subsequent waiters get the same result.
+    private readonly object _exitStatusLock = new();
+    private ProcessExitStatus? _exitStatus;
+
+    private volatile Win32Exception? _lastOperationError;
+
+    // Recorded only for processes started by this library, wall-clock for display, timestamps for measuring.
+    private TimeProvider? _timeProvider;
+    private DateTimeOffset _startTime, _exitTime;
+    private long _startTimestamp, _exitTimestamp;
+
+    // Registered with ProcessStartOptions.ShutdownToken, unregistered when the exit is observed or the handle is disposed.
+    private CancellationTokenRegistration _shutdownRegistration;
+
+    // Set for processes started by this library with their own process group (job object on Windows), which TryKillTree requires.
+    private bool _leadsProcessGroup;
+
+    // Handle arrays passed to the OS up to this length are allocated on the stack, longer ones on the heap.
+    private const int MaxStackAllocatedHandleCount = 256;
+    // stdin, stdout and stderr
+    private const int StdioHandleCount = 3;
+
     /// <summary>
     /// Creates a <see cref="T:Microsoft.Win32.SafeHandles.SafeChildProcessHandle" />.
     /// </summary>
@@ -45,6 +71,53 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     /// </summary>
     public int ProcessId { get; init; }
 
+    /// <summary>
+    /// Gets the platform error of the most recent post-start operation that failed.
+    /// </summary>
+    /// <value>
+    /// The error reported by the OS, or <c>null</c> if no operation has failed so far.
+    /// </value>
+    /// <remarks>
+    /// The failing operation still throws, the error is recorded as well and it's not reset by subsequent successful operations.
+    /// </remarks>
+    public Win32Exception? LastOperationError => _lastOperationError;
+
+    /// <summary>
+    /// Gets the wall-clock time when the process was started, or null if it was not started by this library (e.g. opened by <see cref="Open"/>).
+    /// </summary>
+    public DateTimeOffset? StartTime => _timeProvider is null ? null : _startTime;
+
+    /// <summary>
+    /// Gets the wall-clock time when the exit of the process was observed, or null if it has not been observed yet
+    /// or the process was not started by this library.
+    /// </summary>
+    /// <remarks>It's meant for display only, it can precede <see cref="StartTime"/> when the system clock was adjusted in the meantime.</remarks>
+    public DateTimeOffset? ExitTime => _timeProvider is null || _exitStatus is null ? null : _exitTime;
+
+    /// <summary>
+    /// Gets the time that elapsed from starting the process to observing its exit (or to now, if it has not been observed yet),
+    /// or null if the process was not started by this library.
+    /// </summary>
+    /// <remarks>
+    /// It's measured with a monotonic clock, so unlike <see cref="ExitTime"/> - <see cref="StartTime"/>,
+    /// it's never negative or skewed by adjustments of the system clock. See <see cref="ProcessStartOptions.StartTimeMonotonicSource"/>.
+    /// </remarks>
+    public TimeSpan? Elapsed
+    {
+        get
+        {
+            TimeProvider? timeProvider = _timeProvider;
+            if (timeProvider is null)
+            {
+                return null;
+            }
+
+            return _exitStatus is null
+                ? timeProvider.GetElapsedTime(_startTimestamp)
+                : timeProvider.GetElapsedTime(_startTimestamp, _exitTimestamp);
+        }
+    }
+
     /// <summary>
     /// Creates a <see cref="T:Microsoft.Win32.SafeHandles.SafeChildProcessHandle" /> around a process handle.
     /// </summary>
@@ -83,11 +156,16 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     /// Starts a new process.
     /// </summary>
     /// <param name="options">The process start options.</param>
-    /// <param name="input">The handle to use for standard input, or <see langword="null"/> to provide no input.</param>
+    /// <param name="input">
+    /// The handle to use for standard input, or <see langword="null"/> to provide no input.
+    /// It can be any readable handle, including the read end of an existing pipe (e.g. the output of another process),
+    /// which is handed to the child as-is, without creating a new pipe or copying any data.
+    /// </param>
     /// <param name="output">The handle to use for standard output, or <see langword="null"/> to discard output.</param>
     /// <param name="error">The handle to use for standard error, or <see langword="null"/> to discard error.</param>
     /// <returns>A handle to the started process.</returns>
     /// <exception cref="ArgumentNullException">Thrown when <paramref name="options"/> is null.</exception>
+    /// <exception cref="ArgumentException">Thrown when <paramref name="input"/> is opened only for writing.</exception>
     public static SafeChildProcessHandle Start(ProcessStartOptions options, SafeFileHandle? input, SafeFileHandle? output, SafeFileHandle? error)
     {
         return StartInternal(options, input, output, error, createSuspended: false, detached: false);
@@ -134,6 +212,24 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     {
         ArgumentNullException.ThrowIfNull(options);
 
+        if (options.TraceChildWithPtrace)
+        {
+            if (!OperatingSystem.IsLinux())
+            {
+                throw new PlatformNotSupportedException("Tracing the child process with ptrace is supported only on Linux.");
+            }
+            else if (createSuspended)
+            {
+                throw new InvalidOperationException("A traced process cannot be started suspended, it stops at exec on its own.");
+            }
+        }
+
+        // The handle is duplicated onto the child's standard input as-is, a write-only handle would make every read fail.
+        if (input is not null && input.IsWriteOnly())
+        {
+            throw new ArgumentException("The standard input handle must be readable. Did you pass the write end of a pipe?", nameof(input));
+        }
+
         SafeFileHandle? nullHandle = null;
 
         if (input is null || output is null || error is null)
@@ -145,12 +241,44 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
             error ??= nullHandle;
         }
 
+        SafeHandle[] inheritedHandles = detached ? [] : options.GetInheritedHandlesSnapshot();
+        int addedRefCount = 0;
+
         try
         {
-            return StartCore(options, input, output, error, createSuspended, detached);
+            // The handles are owned by the caller. We just keep them alive (and their values valid) until the child has inherited them.
+            for (; addedRefCount < inheritedHandles.Length; addedRefCount++)
+            {
+                bool success = false;
+                inheritedHandles[addedRefCount].DangerousAddRef(ref success);
+            }
+
+            SafeChildProcessHandle processHandle = StartCore(options, inheritedHandles, input, output, error, createSuspended, detached);
+            processHandle.RecordStart(options.StartTimeMonotonicSource ?? TimeProvider.System);
+            processHandle._leadsProcessGroup = options.CreateNewProcessGroup || detached;
+            if (!detached && options.ShutdownToken.CanBeCanceled)
+            {
+                processHandle.RegisterShutdown(options.ShutdownToken, options.ShutdownGracePeriod, options.CreateNewProcessGroup);
+            }
+            return processHandle;
+        }
+        catch (Win32Exception ex) when (ex.NativeErrorCode == 2 && options.UsesFileNameResolutionCache) // ENOENT and ERROR_FILE_NOT_FOUND
+        {
+            FileNameResolutionCache.Invalidate(options.FileName);
+            throw;
+        }
+        catch (Win32Exception ex) when (ex.NativeErrorCode == 2 && options.IsFileNameResolved && !File.Exists(options.FileName))
+        {
+            // The same error is reported for a missing working directory, so we check what is actually missing.
+            throw new Win32Exception(ex.NativeErrorCode, $"The pre-resolved executable '{options.FileName}' could not be found.");
         }
         finally
         {
+            for (int i = 0; i < addedRefCount; i++)
+            {
+                inheritedHandles[i].DangerousRelease();
+            }
+
             // DESIGN: avoid deadlocks and the need of users being aware of how pipes work by closing the child handles in the parent process.
             // Close the child handles in the parent process, so the pipe will signal EOF when the child exits.
             // Otherwise, the parent process will keep the write end of the pipe open, and any read operations will hang.
@@ -175,16 +303,43 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
         }
     }
 
+    // The callers pass the arguments they have already copied to argv/command line,
+    // so modifying options.Arguments from the callback does not affect the started process.
+    private static void AuditLaunch(ProcessStartOptions options, string resolvedPath, string[] arguments)
+    {
+        Func<LaunchInfo, bool> callback = options.LaunchAuditCallback!;
+        LaunchInfo launchInfo = new(resolvedPath, arguments, options.GetEffectiveWorkingDirectory(resolvedPath));
+
+        bool allowed;
+        try
+        {
+            allowed = callback(launchInfo);
+        }
+        catch (Exception ex)
+        {
+            throw new LaunchDeniedException(launchInfo, ex);
+        }
+
+        if (!allowed)
+        {
+            throw new LaunchDeniedException(launchInfo);
+        }
+    }
+
     /// <summary>
     /// Waits for the process to exit without a timeout.
     /// </summary>
     /// <returns>The exit status of the process.</returns>
     /// <exception cref="InvalidOperationException">Thrown when the handle is invalid.</exception>
+    /// <remarks>
+    /// The calling thread is blocked directly in the OS wait (waitid/kqueue on Unix, WaitForSingleObject on Windows).
+    /// The thread pool is not used, so it works also when the thread pool is disabled or saturated.
+    /// </remarks>
     public ProcessExitStatus WaitForExit()
     {
         Validate();
 
-        return WaitForExitCore();
+        return _exitStatus ?? WaitForExitCore();
     }
 
     /// <summary>
@@ -198,9 +353,62 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     {
         Validate();
 
+        if (_exitStatus is not null)
+        {
+            exitStatus = _exitStatus;
+            return true;
+        }
+
         return TryWaitForExitCore(GetTimeoutInMilliseconds(timeout), out exitStatus);
     }
 
+    /// <summary>
+    /// Waits for the process to exit without a timeout, invoking a heartbeat callback periodically while waiting.
+    /// </summary>
+    /// <param name="heartbeatInterval">How often <paramref name="heartbeat"/> is invoked.</param>
+    /// <param name="heartbeat">The callback, e.g. touching a liveness file or pinging a watchdog.</param>
+    /// <returns>The exit status of the process.</returns>
+    /// <exception cref="ArgumentNullException">Thrown when <paramref name="heartbeat"/> is null.</exception>
+    /// <exception cref="ArgumentOutOfRangeException">Thrown when <paramref name="heartbeatInterval"/> is zero, negative or infinite.</exception>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid.</exception>
+    /// <remarks>
+    /// It keeps external liveness checks of the parent happy during long waits for a child.
+    /// The callback is invoked on the calling thread, every time the interval elapses before the process exits, and never after it exited.
+    /// Exceptions thrown by the callback end the wait and are propagated, the process keeps running.
+    /// </remarks>
+    public ProcessExitStatus WaitForExitWithHeartbeat(TimeSpan heartbeatInterval, Action heartbeat)
+    {
+        ArgumentOutOfRangeException.ThrowIfLessThanOrEqual(heartbeatInterval, TimeSpan.Zero);
+        ArgumentNullException.ThrowIfNull(heartbeat);
+
+        ProcessExitStatus? exitStatus;
+        while (!TryWaitForExit(heartbeatInterval, out exitStatus))
+        {
+            heartbeat();
+        }
+
+        return exitStatus;
+    }
+
+    /// <summary>
+    /// Waits for the next state change of the process (exit, stop or continue) and returns the unmodified status word reported by waitpid.
+    /// </summary>
+    /// <returns>The raw wait status, to be decoded with the WIFEXITED/WIFSIGNALED/WIFSTOPPED/WIFCONTINUED family of macros.</returns>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid or the process has already been waited for.</exception>
+    /// <exception cref="PlatformNotSupportedException">Thrown on Windows.</exception>
+    /// <exception cref="Win32Exception">Thrown when the wait fails.</exception>
+    /// <remarks>
+    /// It's meant for advanced scenarios like tracing, which need to observe stopped and continued states or exotic flags
+    /// that are lost by <see cref="ProcessExitStatus"/>. Stops and continues are consumed, so every call reports a new state change.
+    /// When the process exits, it's reaped and its exit status is cached, so <see cref="WaitForExit"/> returns it afterwards.
+    /// </remarks>
+    public int WaitForRawStatus()
+    {
+        Validate();
+
+        return WaitForRawStatusCore();
+    }
+
     /// <summary>
     /// Waits for the process to exit within the specified timeout.
     /// If the process does not exit before the timeout, it is killed and then waited for exit.
@@ -212,7 +420,7 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     {
         Validate();
 
-        return WaitForExitOrKillOnTimeoutCore(GetTimeoutInMilliseconds(timeout));
+        return _exitStatus ?? WaitForExitOrKillOnTimeoutCore(GetTimeoutInMilliseconds(timeout));
     }
 
     /// <summary>
@@ -231,7 +439,7 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     {
         Validate();
 
-        return WaitForExitAsyncCore(cancellationToken);
+        return _exitStatus is not null ? Task.FromResult(_exitStatus) : WaitForExitAsyncCore(cancellationToken);
     }
 
     /// <summary>
@@ -252,7 +460,7 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     {
         Validate();
 
-        return WaitForExitOrKillOnCancellationAsyncCore(cancellationToken);
+        return _exitStatus is not null ? Task.FromResult(_exitStatus) : WaitForExitOrKillOnCancellationAsyncCore(cancellationToken);
     }
 
     /// <summary>
@@ -263,11 +471,27 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     /// </returns>
     /// <exception cref="InvalidOperationException">Thrown when the handle is invalid.</exception>
     /// <exception cref="Win32Exception">Thrown when the kill operation fails for reasons other than the process having already exited.</exception>
+    /// <remarks>
+    /// When the process has already exited, no termination request is issued at all.
+    /// On Unix, it prevents signaling a zombie process or another process that reused its PID.
+    /// </remarks>
     public bool Kill()
     {
         Validate();
 
-        return KillCore(throwOnError: true, entireProcessGroup: false);
+        if (TryGetExitStatus(canceled: false, out _))
+        {
+            return false;
+        }
+
+        try
+        {
+            return KillCore(throwOnError: true, entireProcessGroup: false);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
     }
 
     /// <summary>
@@ -288,7 +512,92 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     {
         Validate();
 
-        return KillCore(throwOnError: true, entireProcessGroup: true);
+        try
+        {
+            return KillCore(throwOnError: true, entireProcessGroup: true);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// Terminates the entire process group and reports which processes were found and which of them survived.
+    /// </summary>
+    /// <param name="settleTimeout">The maximum time to wait for all the processes to terminate.</param>
+    /// <returns>The report of the processes that were found and the ones that were still running when <paramref name="settleTimeout"/> elapsed.</returns>
+    /// <exception cref="InvalidOperationException">
+    /// Thrown when the handle is invalid, or the process was not started with <see cref="ProcessStartOptions.CreateNewProcessGroup"/>=true
+    /// (this includes processes obtained via <see cref="Open"/>).
+    /// </exception>
+    /// <exception cref="Win32Exception">Thrown when the kill operation fails for reasons other than the processes having already exited.</exception>
+    /// <remarks>
+    /// <para>
+    /// Tree kill is inherently racy: new processes can be spawned while the tree is being terminated and some processes exit on their own.
+    /// That is why the members are enumerated before the kill and then, after waiting for the ones that are still running to exit,
+    /// until they are all gone or the settle timeout elapses. Processes that joined the tree in the meantime are killed and reported as well.
+    /// </para>
+    /// <para>
+    /// Requires the process to have been started with <see cref="ProcessStartOptions.CreateNewProcessGroup"/>=true on all platforms,
+    /// as without its own process group the tree can't be told apart from the other processes of the parent's group.
+    /// On Linux, the members of the process group are found by reading /proc/{pid}/stat. On macOS, proc_listpids is used.
+    /// On Windows, the processes assigned to the job object are reported (processes that broke away from the job are not found).
+    /// </para>
+    /// <para>
+    /// The root process is reaped as part of the operation, its exit status remains available via <see cref="WaitForExit"/>.
+    /// </para>
+    /// </remarks>
+    public KillTreeResult TryKillTree(TimeSpan settleTimeout)
+    {
+        Validate();
+        if (!_leadsProcessGroup)
+        {
+            throw new InvalidOperationException("Cannot terminate the process tree because the process was not started with CreateNewProcessGroup=true.");
+        }
+
+        try
+        {
+            List<int> processIds = GetProcessGroupMembersCore();
+            HashSet<int> foundProcessIds = new(processIds);
+            KillCore(throwOnError: true, entireProcessGroup: true);
+
+            TimeoutHelper timeout = TimeoutHelper.Start(settleTimeout);
+            while (true)
+            {
+                // Reap our own child, so it's not reported as a (zombie) survivor.
+                TryGetExitStatus(canceled: false, out _);
+
+                List<int> survivors = GetProcessGroupMembersCore();
+                foreach (int survivor in survivors)
+                {
+                    if (foundProcessIds.Add(survivor))
+                    {
+                        processIds.Add(survivor);
+                    }
+                }
+
+                if (survivors.Count == 0 || timeout.HasExpired)
+                {
+                    return new(processIds, survivors);
+                }
+
+                // A new child could have been spawned just before the kill, kill it as well.
+                KillCore(throwOnError: false, entireProcessGroup: true);
+
+                foreach (int survivor in survivors)
+                {
+                    if (!WaitForProcessGroupMemberExitCore(survivor, timeout.GetRemainingMilliseconds()))
+                    {
+                        break; // The settle timeout has elapsed, the next enumeration reports who survived.
+                    }
+                }
+            }
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
     }
 
     /// <summary>
@@ -300,7 +609,95 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     {
         Validate();
 
-        ResumeCore();
+        try
+        {
+            ResumeCore();
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// Waits for a debugger to attach to the suspended process and then resumes it.
+    /// </summary>
+    /// <param name="timeout">The maximum time to wait for the debugger. The process is resumed when it elapses.</param>
+    /// <returns><c>true</c> if a debugger was attached before the process was resumed; otherwise, <c>false</c>.</returns>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid or the process was not started with <see cref="StartSuspended"/>.</exception>
+    /// <exception cref="PlatformNotSupportedException">Thrown on platforms other than Windows.</exception>
+    /// <exception cref="Win32Exception">Thrown when the resume operation fails.</exception>
+    /// <remarks>
+    /// Supports "launch suspended, attach debugger, resume" workflows: the child does not execute any code
+    /// until the debugger (e.g. Visual Studio or WinDbg) attaches to <see cref="ProcessId"/> or the timeout elapses.
+    /// </remarks>
+    public bool ResumeAfterDebuggerAttach(TimeSpan timeout)
+    {
+        Validate();
+
+        try
+        {
+            return ResumeAfterDebuggerAttachCore(GetTimeoutInMilliseconds(timeout));
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// Asks a GUI process to close by posting WM_CLOSE to its main windows. Windows only.
+    /// </summary>
+    /// <returns><c>true</c> if a main window was found and the message was posted; <c>false</c> if the process has no main window (e.g. a console app).</returns>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid.</exception>
+    /// <exception cref="PlatformNotSupportedException">Thrown on platforms other than Windows.</exception>
+    /// <exception cref="Win32Exception">Thrown when a main window was found, but the message could not be posted to it.</exception>
+    /// <remarks>
+    /// <para>
+    /// Main windows are the visible top-level windows without an owner, the same ones <see cref="System.Diagnostics.Process.CloseMainWindow"/> considers.
+    /// The message is posted to all of them and the method returns without waiting, the app may save its state, ask the user what to do or ignore the request.
+    /// </para>
+    /// <para>
+    /// It's the polite first step of a graceful shutdown: call it, wait for a grace period with <see cref="TryWaitForExit"/>
+    /// and fall back to <see cref="Kill"/> when the process is still running.
+    /// </para>
+    /// </remarks>
+    public bool CloseMainWindow()
+    {
+        Validate();
+
+        try
+        {
+            return CloseMainWindowCore();
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// Stops tracing a process started with <see cref="ProcessStartOptions.TraceChildWithPtrace"/> and resumes it.
+    /// </summary>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid.</exception>
+    /// <exception cref="PlatformNotSupportedException">Thrown on platforms other than Linux.</exception>
+    /// <exception cref="Win32Exception">Thrown when the process is not stopped, is not traced or the calling thread is not its tracer.</exception>
+    /// <remarks>
+    /// It must be called from the thread that started the process, while the process is in a ptrace stop
+    /// (e.g. the initial exec stop or a stop reported by <see cref="WaitForRawStatus"/>).
+    /// </remarks>
+    public void DetachTracer()
+    {
+        Validate();
+
+        try
+        {
+            DetachTracerCore();
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
     }
 
     /// <summary>
@@ -326,7 +723,50 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
 
         Validate();
 
-        SendSignalCore(signal, entireProcessGroup: false);
+        try
+        {
+            SendSignalCore(signal, entireProcessGroup: false);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// Sends a signal that makes the process terminate with a core dump and reports where the core file is expected. Linux only.
+    /// </summary>
+    /// <param name="signal">The signal to send. Its default action must be to dump core, which is the case for <see cref="PosixSignal.SIGQUIT"/>.</param>
+    /// <returns>
+    /// The expected path of the core file, or <c>null</c> when no core file is expected: the core file size limit of the process is zero,
+    /// the kernel pipes cores to a handler (e.g. systemd-coredump) or the core pattern can't be predicted.
+    /// </returns>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid or the process has already been waited for.</exception>
+    /// <exception cref="ArgumentOutOfRangeException">Thrown when the signal value is not supported.</exception>
+    /// <exception cref="PlatformNotSupportedException">Thrown on platforms other than Linux.</exception>
+    /// <exception cref="Win32Exception">Thrown when the signal could not be sent.</exception>
+    /// <remarks>
+    /// It aids debugging stuck children. The process is terminated, the limits are respected (RLIMIT_CORE of the process is not changed)
+    /// and the file is written by the kernel according to /proc/sys/kernel/core_pattern, a relative pattern is relative to the working directory of the process.
+    /// The file may still be being written when this method returns, wait for the process to exit before reading it.
+    /// </remarks>
+    public string? DumpCore(PosixSignal signal = PosixSignal.SIGQUIT)
+    {
+        if (!Enum.IsDefined(signal))
+        {
+            throw new ArgumentOutOfRangeException(nameof(signal));
+        }
+
+        Validate();
+
+        try
+        {
+            return DumpCoreCore(signal);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
     }
 
     /// <summary>
@@ -351,9 +791,228 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
 
         Validate();
 
-        SendSignalCore(signal, entireProcessGroup: true);
+        try
+        {
+            SendSignalCore(signal, entireProcessGroup: true);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
     }
-    
+
+    /// <summary>
+    /// Gets the full path of the executable that the process is running.
+    /// </summary>
+    /// <returns>
+    /// The full path of the executable; <c>null</c> if it could not be determined,
+    /// for example due to insufficient permissions or because the process has already exited.
+    /// </returns>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid.</exception>
+    /// <remarks>
+    /// On Linux, the path is resolved by reading the /proc/{pid}/exe symbolic link.
+    /// On macOS, proc_pidpath is used.
+    /// On Windows, QueryFullProcessImageName is used.
+    /// The path is resolved by the operating system, so it may differ from <see cref="ProcessStartOptions.FileName"/> when symbolic links are involved.
+    /// </remarks>
+    public string? GetExecutablePath()
+    {
+        Validate();
+
+        return GetExecutablePathCore();
+    }
+
+    /// <summary>
+    /// Gets the paths of the executable and the shared libraries loaded by the process. Linux only.
+    /// </summary>
+    /// <returns>The distinct paths of the process' file-backed executable mappings, in the order of their addresses.</returns>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid or the process has already been waited for.</exception>
+    /// <exception cref="PlatformNotSupportedException">Thrown on platforms other than Linux.</exception>
+    /// <exception cref="UnauthorizedAccessException">Thrown when the caller is not allowed to inspect the memory mappings of the process.</exception>
+    /// <remarks>
+    /// It's meant for diagnostics, e.g. to verify that the expected versions of the libraries were picked up (LD_LIBRARY_PATH took effect).
+    /// The list is parsed from /proc/{pid}/maps, it's a snapshot that can change as the process loads or unloads libraries.
+    /// Files that were deleted or replaced after being mapped are reported by the kernel with a " (deleted)" suffix.
+    /// It's empty when the process has already exited, but it has not been waited for yet.
+    /// </remarks>
+    public IReadOnlyList<string> GetLoadedModules()
+    {
+        Validate();
+
+        return GetLoadedModulesCore();
+    }
+
+    /// <summary>
+    /// Samples the CPU time consumed by the process twice over the specified interval and returns its CPU usage during that window.
+    /// </summary>
+    /// <param name="samplingInterval">
+    /// The time to wait between the two samples. The calling thread is blocked for this duration,
+    /// use <see cref="GetCpuUsagePercentageAsync"/> to avoid that.
+    /// </param>
+    /// <returns>
+    /// The CPU usage as a percentage of a single core. A process that keeps multiple cores busy reports more than 100,
+    /// up to 100 multiplied by <see cref="Environment.ProcessorCount"/>.
+    /// </returns>
+    /// <exception cref="ArgumentOutOfRangeException">Thrown when <paramref name="samplingInterval"/> is not positive.</exception>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid or the process has already been waited for.</exception>
+    /// <exception cref="Win32Exception">Thrown when the CPU time of the process could not be obtained.</exception>
+    /// <remarks>
+    /// On Linux, the CPU time is read from /proc/{pid}/stat, so its resolution is limited to clock ticks (usually 10ms).
+    /// On macOS, proc_pid_rusage is used.
+    /// On Windows, GetProcessTimes is used.
+    /// </remarks>
+    public double GetCpuUsagePercentage(TimeSpan samplingInterval)
+    {
+        ArgumentOutOfRangeException.ThrowIfLessThanOrEqual(samplingInterval, TimeSpan.Zero);
+        Validate();
+
+        try
+        {
+            TimeSpan startCpuTime = GetTotalProcessorTimeCore();
+            long startTimestamp = Stopwatch.GetTimestamp();
+
+            Thread.Sleep(samplingInterval);
+
+            return GetCpuUsagePercentageSince(startCpuTime, startTimestamp);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// Samples the CPU time consumed by the process twice over the specified interval, without blocking the calling thread,
+    /// and returns its CPU usage during that window.
+    /// </summary>
+    /// <param name="samplingInterval">The time to wait between the two samples.</param>
+    /// <param name="cancellationToken">A cancellation token that can be used to cancel the wait between the samples.</param>
+    /// <returns>
+    /// A task that represents the asynchronous sampling. The task result contains the CPU usage as a percentage of a single core,
+    /// the same as for <see cref="GetCpuUsagePercentage"/>.
+    /// </returns>
+    /// <exception cref="ArgumentOutOfRangeException">Thrown when <paramref name="samplingInterval"/> is not positive.</exception>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid or the process has already been waited for.</exception>
+    /// <exception cref="Win32Exception">Thrown when the CPU time of the process could not be obtained.</exception>
+    /// <exception cref="OperationCanceledException">Thrown when the cancellation token is canceled.</exception>
+    public Task<double> GetCpuUsagePercentageAsync(TimeSpan samplingInterval, CancellationToken cancellationToken = default)
+    {
+        ArgumentOutOfRangeException.ThrowIfLessThanOrEqual(samplingInterval, TimeSpan.Zero);
+        Validate();
+
+        try
+        {
+            TimeSpan startCpuTime = GetTotalProcessorTimeCore();
+            long startTimestamp = Stopwatch.GetTimestamp();
+
+            return GetCpuUsagePercentageAsyncCore(startCpuTime, startTimestamp, samplingInterval, cancellationToken);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    private async Task<double> GetCpuUsagePercentageAsyncCore(TimeSpan startCpuTime, long startTimestamp, TimeSpan samplingInterval,
+        CancellationToken cancellationToken)
+    {
+        await Task.Delay(samplingInterval, cancellationToken).ConfigureAwait(false);
+
+        try
+        {
+            return GetCpuUsagePercentageSince(startCpuTime, startTimestamp);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    private double GetCpuUsagePercentageSince(TimeSpan startCpuTime, long startTimestamp)
+    {
+        TimeSpan endCpuTime = GetTotalProcessorTimeCore();
+        TimeSpan elapsed = Stopwatch.GetElapsedTime(startTimestamp);
+
+        return (endCpuTime - startCpuTime).TotalMilliseconds / elapsed.TotalMilliseconds * 100;
+    }
+
+    /// <summary>
+    /// Gets the number of handles that are currently open in the process.
+    /// </summary>
+    /// <returns>The number of open handles; -1 on non-Windows platforms or when the process has already exited.</returns>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid.</exception>
+    /// <exception cref="Win32Exception">Thrown when the handle count could not be obtained.</exception>
+    /// <remarks>
+    /// It's meant for leak detection in monitored child processes: a count that keeps growing indicates a handle leak.
+    /// On Windows, GetProcessHandleCount is used. Unix has no equivalent of the Windows handle model, so -1 is returned.
+    /// </remarks>
+    public int GetHandleCount()
+    {
+        Validate();
+
+        try
+        {
+            return GetHandleCountCore();
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// Gets the current scheduling priority of the process.
+    /// </summary>
+    /// <returns>The priority of the process.</returns>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid or the process has already been waited for (Unix).</exception>
+    /// <exception cref="Win32Exception">Thrown when the priority could not be obtained.</exception>
+    /// <remarks>
+    /// On Windows, GetPriorityClass is used. On Unix, the nice value reported by getpriority is mapped to the closest <see cref="ProcessPriority"/>.
+    /// </remarks>
+    public ProcessPriority GetPriority()
+    {
+        Validate();
+
+        try
+        {
+            return GetPriorityCore();
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
+    /// <summary>
+    /// Changes the scheduling priority of the running process.
+    /// </summary>
+    /// <param name="priority">The new priority.</param>
+    /// <exception cref="ArgumentOutOfRangeException">Thrown when <paramref name="priority"/> is not a defined value.</exception>
+    /// <exception cref="InvalidOperationException">Thrown when the handle is invalid or the process has already been waited for (Unix).</exception>
+    /// <exception cref="Win32Exception">Thrown when the priority could not be changed, e.g. raising it on Unix without the required privileges.</exception>
+    /// <remarks>
+    /// It allows supervisors to dynamically throttle a child, e.g. lower its priority while the system is busy.
+    /// On Windows, SetPriorityClass is used. On Unix, setpriority is used with the nice values listed in <see cref="ProcessPriority"/>.
+    /// </remarks>
+    public void SetPriority(ProcessPriority priority)
+    {
+        if (!Enum.IsDefined(priority))
+        {
+            throw new ArgumentOutOfRangeException(nameof(priority), priority, "The priority is not a defined value.");
+        }
+
+        Validate();
+
+        try
+        {
+            SetPriorityCore(priority);
+        }
+        catch (Win32Exception ex) when (RecordOperationError(ex))
+        {
+            throw;
+        }
+    }
+
     /// <summary>
     /// This is an INTERNAL method that can be used as PERF optimization
     /// in cases where we know that both STD OUT and STDERR got closed,
@@ -365,19 +1024,137 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
     {
         Validate();
 
-        return TryGetExitCodeCore(out exitCode, out signal);
+        if (TryGetExitStatus(canceled: false, out ProcessExitStatus? exitStatus))
+        {
+            exitCode = exitStatus.ExitCode;
+            signal = exitStatus.Signal;
+            return true;
+        }
+
+        exitCode = default;
+        signal = null;
+        return false;
     }
 
     internal bool TryGetExitStatus(bool canceled, [NotNullWhen(true)] out ProcessExitStatus? exitStatus)
     {
-        if (TryGetExitCodeCore(out int exitCode, out PosixSignal? signal))
+        ProcessExitStatus? cached = _exitStatus;
+        if (cached is null)
         {
-            exitStatus = new(exitCode, canceled, signal);
-            return true;
+            lock (_exitStatusLock)
+            {
+                if (_exitStatus is null && TryGetExitCodeCore(out int exitCode, out PosixSignal? signal))
+                {
+                    SetExitStatus(new(exitCode, false, signal));
+                }
+
+                cached = _exitStatus;
+            }
         }
 
-        exitStatus = null;
-        return false;
+        if (cached is null)
+        {
+            exitStatus = null;
+            return false;
+        }
+
+        exitStatus = canceled ? new(cached.ExitCode, canceled, cached.Signal) : cached;
+        return true;
+    }
+
+    private void RecordStart(TimeProvider timeProvider)
+    {
+        _startTimestamp = timeProvider.GetTimestamp();
+        _startTime = timeProvider.GetUtcNow();
+        _timeProvider = timeProvider;
+    }
+
+    private void RegisterShutdown(CancellationToken shutdownToken, TimeSpan gracePeriod, bool entireProcessGroup)
+    {
+        // The callback runs on the thread that cancels the token, which must not be blocked for the grace period.
+        _shutdownRegistration = shutdownToken.UnsafeRegister(_ => _ = ShutdownAsync(gracePeriod, entireProcessGroup), null);
+    }
+
+    private async Task ShutdownAsync(TimeSpan gracePeriod, bool entireProcessGroup)
+    {
+        bool addedRef = false;
+        try
+        {
+            // Keep the handle (and the pidfd on Linux) valid, so we never signal a recycled PID.
+            DangerousAddRef(ref addedRef);
+
+            if (TryGetExitStatus(canceled: false, out _))
+            {
+                return;
+            }
+
+            bool requested;
+            try
+            {
+                requested = RequestGracefulExitCore(entireProcessGroup);
+            }
+            catch (Win32Exception ex)
+            {
+                RecordOperationError(ex);
+                requested = false;
+            }
+
+            if (requested && gracePeriod > TimeSpan.Zero)
+            {
+                using CancellationTokenSource graceTimeout = new(gracePeriod);
+                try
+                {
+                    await WaitForExitAsyncCore(graceTimeout.Token).ConfigureAwait(false);
+                    return;
+                }
+                catch (OperationCanceledException)
+                {
+                }
+            }
+
+            KillCore(throwOnError: false, entireProcessGroup);
+        }
+        catch (ObjectDisposedException)
+        {
+            // The handle was disposed, it's not ours to shut down anymore.
+        }
+        catch (Win32Exception ex)
+        {
+            // Nobody awaits the shutdown, the error is reported the same way as for the other operations.
+            RecordOperationError(ex);
+        }
+        finally
+        {
+            if (addedRef)
+            {
+                DangerousRelease();
+            }
+        }
+    }
+
+    protected override void Dispose(bool disposing)
+    {
+        if (disposing)
+        {
+            _shutdownRegistration.Dispose();
+        }
+
+        base.Dispose(disposing);
+    }
+
+    // Must be called under _exitStatusLock.
+    private void SetExitStatus(ProcessExitStatus exitStatus)
+    {
+        _shutdownRegistration.Unregister();
+
+        if (_timeProvider is { } timeProvider)
+        {
+            _exitTimestamp = timeProvider.GetTimestamp();
+            _exitTime = timeProvider.GetUtcNow();
+        }
+
+        // Published last, so whoever observes the exit status observes the exit time too.
+        Volatile.Write(ref _exitStatus, exitStatus);
     }
 
     private void Validate()
@@ -388,6 +1165,15 @@ public sealed partial class SafeChildProcessHandle : SafeHandle
         }
     }
 
+    // Used as an exception filter by the public operations: it records the error for LastOperationError
+    // and returns false, so the exception propagates unchanged (the catch blocks are never entered).
+    // Code paths that swallow the error (like the background shutdown) call it directly.
+    private bool RecordOperationError(Win32Exception error)
+    {
+        _lastOperationError = error;
+        return false;
+    }
+
     internal static int GetTimeoutInMilliseconds(TimeSpan? timeout)
         => timeout switch
         {
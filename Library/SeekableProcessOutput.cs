@@ -0,0 +1,59 @@
+using System.IO;
+
+namespace System.TBA;
+
+/// <summary>
+/// The standard output and error of a process captured into seekable streams, kept in memory or spilled to temporary files.
+/// </summary>
+public sealed class SeekableProcessOutput : IDisposable
+{
+    internal SeekableProcessOutput(ProcessExitStatus exitStatus, Stream standardOutput, Stream standardError, int processId)
+    {
+        ExitStatus = exitStatus;
+        StandardOutput = standardOutput;
+        StandardError = standardError;
+        ProcessId = processId;
+    }
+
+    /// <summary>
+    /// Gets the exit status of the process after it has terminated.
+    /// </summary>
+    public ProcessExitStatus ExitStatus { get; }
+
+    /// <summary>
+    /// Gets the captured standard output.
+    /// </summary>
+    /// <remarks>
+    /// The stream is readable and seekable and initially positioned at the beginning.
+    /// Set <see cref="Stream.Position"/> to zero to read the output again.
+    /// </remarks>
+    public Stream StandardOutput { get; }
+
+    /// <summary>
+    /// Gets the captured standard error.
+    /// </summary>
+    /// <remarks>
+    /// It's captured the same way as <see cref="StandardOutput"/>, with a spill threshold of its own.
+    /// </remarks>
+    public Stream StandardError { get; }
+
+    /// <summary>
+    /// Gets the process ID that was used when it was running.
+    /// </summary>
+    public int ProcessId { get; }
+
+    /// <summary>
+    /// Gets a value indicating whether the standard output or error exceeded <see cref="ProcessStartOptions.CapturedOutputSpillThresholdBytes"/>
+    /// and was moved to a temporary file.
+    /// </summary>
+    public bool IsSpilledToFile => StandardOutput is FileStream || StandardError is FileStream;
+
+    /// <summary>
+    /// Closes <see cref="StandardOutput"/> and <see cref="StandardError"/> and deletes their temporary files, if any.
+    /// </summary>
+    public void Dispose()
+    {
+        StandardOutput.Dispose();
+        StandardError.Dispose();
+    }
+}
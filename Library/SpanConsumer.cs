@@ -0,0 +1,11 @@
+namespace System.TBA;
+
+/// <summary>
+/// Represents a callback that consumes a chunk of raw bytes read from the output of a process.
+/// </summary>
+/// <param name="chunk">The bytes that were read. It's valid only for the duration of the call.</param>
+/// <remarks>
+/// The span points to a buffer that is reused by the next read, so it must not escape the callback:
+/// copy the bytes (or the values parsed from them) if they are needed later.
+/// </remarks>
+public delegate void SpanConsumer(ReadOnlySpan<byte> chunk);
@@ -1,4 +1,6 @@
-﻿namespace Microsoft.Win32.SafeHandles;
+﻿using System.Runtime.InteropServices;
+
+namespace Microsoft.Win32.SafeHandles;
 
 public static partial class SafeFileHandleExtensions
 {
@@ -14,4 +16,21 @@ public static partial class SafeFileHandleExtensions
         return (status.Mode & Interop.Sys.FileTypes.S_IFMT) == Interop.Sys.FileTypes.S_IFIFO ||
                (status.Mode & Interop.Sys.FileTypes.S_IFMT) == Interop.Sys.FileTypes.S_IFSOCK;
     }
+
+    private static bool IsTerminalCore(SafeFileHandle handle) => isatty((int)handle.DangerousGetHandle()) == 1;
+
+    private static bool IsWriteOnlyCore(SafeFileHandle handle)
+    {
+        // Both values are the same on Linux and macOS.
+        const int F_GETFL = 3, O_ACCMODE = 3, O_WRONLY = 1;
+
+        int flags = fcntl((int)handle.DangerousGetHandle(), F_GETFL);
+        return flags != -1 && (flags & O_ACCMODE) == O_WRONLY;
+    }
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial int isatty(int fd);
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial int fcntl(int fd, int cmd);
 }
@@ -8,6 +8,22 @@ public static partial class SafeFileHandleExtensions
     private static bool IsPipeCore(SafeFileHandle handle)
         => Interop.Kernel32.GetFileType(handle) == Interop.Kernel32.FileTypes.FILE_TYPE_PIPE;
 
+    // FILE_TYPE_CHAR is reported also for NUL, only a console handle has a console mode.
+    private static bool IsTerminalCore(SafeFileHandle handle)
+        => Interop.Kernel32.GetConsoleMode(handle, out _);
+
+    private static unsafe bool IsWriteOnlyCore(SafeFileHandle handle)
+    {
+        const uint FileAccessInformation = 8;
+        const uint FILE_READ_DATA = 0x0001;
+
+        uint accessFlags;
+        int status = Interop.NtDll.NtQueryInformationFile(handle, out _, &accessFlags, sizeof(uint), FileAccessInformation);
+
+        // GENERIC_READ is mapped to FILE_READ_DATA (among others) when the handle is opened.
+        return status == 0 && (accessFlags & FILE_READ_DATA) == 0;
+    }
+
     internal static int GetLastWin32ErrorAndDisposeHandleIfInvalid(this SafeFileHandle handle)
     {
         int errorCode = Marshal.GetLastPInvokeError();
@@ -10,5 +10,16 @@ public static partial class SafeFileHandleExtensions
         /// Returns true if the handle represents a socket, a named pipe, or an anonymous pipe.
         /// </summary>
         public bool IsPipe() => !handle.IsInvalid && !handle.IsClosed && IsPipeCore(handle);
+
+        /// <summary>
+        /// Returns true if the handle represents a terminal (a console on Windows).
+        /// </summary>
+        internal bool IsTerminal() => !handle.IsInvalid && !handle.IsClosed && IsTerminalCore(handle);
+
+        /// <summary>
+        /// Returns true if the handle is known to be opened only for writing (e.g. the write end of a pipe).
+        /// </summary>
+        /// <remarks>When the access mode can't be determined, false is returned.</remarks>
+        internal bool IsWriteOnly() => !handle.IsInvalid && !handle.IsClosed && IsWriteOnlyCore(handle);
     }
 }
@@ -72,6 +72,9 @@ if(CMAKE_SYSTEM_NAME STREQUAL "Darwin")
             #endif
         }
     " HAVE_POSIX_SPAWN_START_SUSPENDED)
+
+    # Check for proc_pid_rusage (macOS-specific), used to get CPU time of a process
+    check_symbol_exists(proc_pid_rusage "libproc.h" HAVE_PROC_PID_RUSAGE)
 endif()
 
 # On Linux, check for specific syscalls
@@ -136,6 +139,13 @@ if(CMAKE_SYSTEM_NAME STREQUAL "Linux")
         }
     " HAVE_SYS_TGKILL)
 
+    check_c_source_compiles("
+        #include <sys/ptrace.h>
+        int main() {
+            return ptrace(PTRACE_TRACEME, 0, 0, 0);
+        }
+    " HAVE_PTRACE_TRACEME)
+
 endif()
 
 # Generate the configuration header
@@ -6,10 +6,12 @@
 #include <signal.h>
 #include <errno.h>
 #include <string.h>
+#include <stdio.h>
 #include <stdint.h>
 #include <poll.h>
 #include <time.h>
 #include <sys/time.h>
+#include <sys/resource.h>
 
 #ifdef HAVE_SYS_SYSCALL_H
 #include <sys/syscall.h>
@@ -31,6 +33,15 @@
 #include <spawn.h>
 #endif
 
+#ifdef HAVE_PTRACE_TRACEME
+#include <sys/ptrace.h>
+#endif
+
+#ifdef HAVE_PROC_PID_RUSAGE
+#include <libproc.h>
+#include <mach/mach_time.h>
+#endif
+
 // In the future, we could add support for pidfd on FreeBSD
 #ifdef HAVE_CLONE3
 #define HAVE_PIDFD
@@ -145,6 +156,8 @@ int create_kqueue_cloexec(void) {
 // If create_new_process_group is non-zero, the child process will be created in a new process group
 // If detached is non-zero, the child process will be detached (starts a new session with setsid)
 // If inherited_handles is not NULL and inherited_handles_count > 0, the specified file descriptors will be inherited
+// If trace_with_ptrace is non-zero, the child calls ptrace(PTRACE_TRACEME) before exec and the function returns
+//   once the child has stopped on the SIGTRAP delivered by exec (Linux only)
 int spawn_process(
     const char* path,
     char* const argv[],
@@ -160,7 +173,8 @@ int spawn_process(
     int create_new_process_group,
     int detached,
     const int* inherited_handles,
-    int inherited_handles_count)
+    int inherited_handles_count,
+    int trace_with_ptrace)
 {
 #if defined(HAVE_POSIX_SPAWN) && defined(HAVE_POSIX_SPAWN_CLOEXEC_DEFAULT) && defined(HAVE_POSIX_SPAWN_FILE_ACTIONS_ADDINHERIT_NP)
     // ========== POSIX_SPAWN PATH (macOS) ==========
@@ -180,6 +194,12 @@ int spawn_process(
         return -1;
     }
 #endif
+
+    // Tracing the child with ptrace is implemented only for the fork/exec path
+    if (trace_with_ptrace) {
+        errno = ENOTSUP;
+        return -1;
+    }
     
     pid_t child_pid;
     posix_spawn_file_actions_t file_actions;
@@ -333,6 +353,13 @@ int spawn_process(
         return -1;
     }
 #endif
+
+#ifndef HAVE_PTRACE_TRACEME
+    if (trace_with_ptrace) {
+        errno = ENOTSUP;
+        return -1;
+    }
+#endif
     
     int wait_pipe[2];
     int pidfd = -1;
@@ -533,6 +560,16 @@ int spawn_process(
 #endif
             // When the parent resumes us with SIGCONT, execution continues here
         }
+
+#ifdef HAVE_PTRACE_TRACEME
+        // If tracing is requested, make the parent our tracer. The successful execve below
+        // then delivers SIGTRAP to us, which stops us before the first instruction of the new program.
+        if (trace_with_ptrace) {
+            if (ptrace(PTRACE_TRACEME, 0, NULL, NULL) == -1) {
+                write_errno_and_exit(wait_pipe[1], errno);
+            }
+        }
+#endif
         
         // Execute the program
         // If envp is NULL, use the current environment (environ)
@@ -605,6 +642,33 @@ int spawn_process(
         }
         // Child is now stopped and waiting for SIGCONT
     }
+
+    // If tracing was requested, wait for the child to stop on the SIGTRAP delivered by exec
+    if (trace_with_ptrace) {
+        int status;
+        pid_t wait_result;
+
+        // ptrace stops of a traced child are reported without WUNTRACED
+        while ((wait_result = waitpid(child_pid, &status, 0)) < 0 && errno == EINTR);
+
+        if (wait_result == -1) {
+            int saved_errno = errno;
+#ifdef HAVE_CLONE3
+            close(pidfd);
+#endif
+            errno = saved_errno;
+            return -1;
+        }
+
+        if (!WIFSTOPPED(status) || WSTOPSIG(status) != SIGTRAP) {
+#ifdef HAVE_CLONE3
+            close(pidfd);
+#endif
+            errno = ECHILD;  // Use ECHILD to indicate child state error
+            return -1;
+        }
+        // Child is now stopped at exec and waiting for the tracer
+    }
     
     // Success - return PID and pidfd if requested
     if (out_pid != NULL) {
@@ -678,8 +742,8 @@ int send_signal(int pidfd, int pid, int managed_signal) {
     return kill(pid, native_signal);
 }
 
-#ifndef HAVE_PIDFD
-static int map_status(int status, int* out_exitCode, int* out_signal) {
+// Maps the status word reported by waitpid.
+static int map_wait_status(int status, int* out_exitCode, int* out_signal) {
     if (WIFEXITED(status)) {
         *out_exitCode = WEXITSTATUS(status);
         *out_signal = 0;
@@ -693,7 +757,8 @@ static int map_status(int status, int* out_exitCode, int* out_signal) {
     }
     return -1; // Still running or unknown status
 }
-#else
+
+#ifdef HAVE_PIDFD
 static int map_status(const siginfo_t* info, int* out_exitCode, int* out_signal) {
     switch (info->si_code)
     {
@@ -731,38 +796,34 @@ int try_get_exit_code(int pidfd, int pid, int* out_exitCode, int* out_signal) {
     while ((ret = waitpid(pid, &status, WNOHANG)) < 0 && errno == EINTR);
 
     if (ret > 0) {
-        return map_status(status, out_exitCode, out_signal);
+        return map_wait_status(status, out_exitCode, out_signal);
     }
 #endif
     // Process still running or error
     return -1;
 }
 
-// -1 is a valid exit code, so to distinguish between a normal exit code and an error, we return 0 on success and -1 on error
-int wait_for_exit_and_reap(int pidfd, int pid, int* out_exitCode, int* out_signal) {
+// Waits for the process to exit, but does not reap it (WNOWAIT).
+// Reaping is performed by try_get_exit_code, which the managed code calls exactly once,
+// so concurrent waiters never end up calling waitid/waitpid on an already reaped process.
+// Returns 0 if the process has exited, -1 on error.
+int wait_for_exit(int pidfd, int pid) {
     int ret;
-#ifdef HAVE_PIDFD
     siginfo_t info;
     memset(&info, 0, sizeof(info));
-    while ((ret = waitid(P_PIDFD, pidfd, &info, WEXITED)) < 0 && errno == EINTR);
-
-    if (ret != -1) {
-        return map_status(&info, out_exitCode, out_signal);
-    }
+#ifdef HAVE_PIDFD
+    (void)pid;
+    while ((ret = waitid(P_PIDFD, pidfd, &info, WEXITED | WNOWAIT)) < 0 && errno == EINTR);
 #else
-    int status;
-    while ((ret = waitpid(pid, &status, 0)) < 0 && errno == EINTR);
-
-    if (ret != -1) {
-        return map_status(status, out_exitCode, out_signal);
-    }
+    (void)pidfd;
+    while ((ret = waitid(P_PID, pid, &info, WEXITED | WNOWAIT)) < 0 && errno == EINTR);
 #endif
-    return -1;
+    return ret == -1 ? -1 : 0;
 }
 
-// Try to wait for exit with cancellation support
+// Try to wait for exit with cancellation support, the process is not reaped.
 // Returns -1 on error, 1 on cancellation (data in cancelPipeFd), or 0 if process exited.
-int try_wait_for_exit_cancellable(int pidfd, int pid, int cancelPipeFd, int* out_exitCode, int* out_signal) {
+int try_wait_for_exit_cancellable(int pidfd, int pid, int cancelPipeFd) {
     int ret;
 #if defined(HAVE_KQUEUE) || defined(HAVE_KQUEUEX)
     // macOS and FreeBSD have kqueue which can monitor process exit
@@ -794,7 +855,8 @@ int try_wait_for_exit_cancellable(int pidfd, int pid, int cancelPipeFd, int* out
         close(queue);
 
         // If the target process does not exist at registration time kevent() returns -1 and errno == ESRCH.
-        if (saved_errno == ESRCH && try_get_exit_code(pidfd, pid, out_exitCode, out_signal) != -1)
+        // It means that it has already exited (and possibly got reaped by a concurrent waiter).
+        if (saved_errno == ESRCH)
         {
             return 0;
         }
@@ -841,13 +903,13 @@ int try_wait_for_exit_cancellable(int pidfd, int pid, int cancelPipeFd, int* out
     return -1;
 #endif
 
-    // Process exited - collect exit status
-    return wait_for_exit_and_reap(pidfd, pid, out_exitCode, out_signal);
+    // Process exited, the caller is responsible for collecting the exit status.
+    return 0;
 }
 
-// Try to wait for exit with timeout, but don't kill the process if timeout occurs
+// Try to wait for exit with timeout, but don't kill the process if timeout occurs. The process is not reaped.
 // Returns -1 on error, 1 on timeout, or 0 if process exited.
-int try_wait_for_exit(int pidfd, int pid, int timeout_ms, int* out_exitCode, int* out_signal) {
+int try_wait_for_exit(int pidfd, int pid, int timeout_ms) {
     int ret;
 #if defined(HAVE_KQUEUE) || defined(HAVE_KQUEUEX)
     // macOS and FreeBSD have kqueue which can monitor process exit
@@ -875,7 +937,8 @@ int try_wait_for_exit(int pidfd, int pid, int timeout_ms, int* out_exitCode, int
         close(queue);
 
         // If the target process does not exist at registration time kevent() returns -1 and errno == ESRCH.
-        if (errno == ESRCH && try_get_exit_code(pidfd, pid, out_exitCode, out_signal) != -1)
+        // It means that it has already exited (and possibly got reaped by a concurrent waiter).
+        if (saved_errno == ESRCH)
         {
             return 0;
         }
@@ -908,14 +971,39 @@ int try_wait_for_exit(int pidfd, int pid, int timeout_ms, int* out_exitCode, int
         return 1; // Indicate timeout (not an error, but process didn't exit)
     }
 
-    // Process exited - collect exit status
-    return wait_for_exit_and_reap(pidfd, pid, out_exitCode, out_signal);
+    // Process exited, the caller is responsible for collecting the exit status.
+    return 0;
 }
 
 
-// -1 is a valid exit code, so to distinguish between a normal exit code and an error, we return 0 on success and -1 on error
-int wait_for_exit_or_kill_on_timeout(int pidfd, int pid, int timeout_ms, int* out_exitCode, int* out_signal, int* out_timeout) {
-    int ret = try_wait_for_exit(pidfd, pid, timeout_ms, out_exitCode, out_signal);
+// Waits for any process (not necessarily a child) to exit, used for the members of a process group. The process is not reaped.
+// Returns -1 on error, 1 on timeout, or 0 if process exited (or does not exist anymore).
+int wait_for_process_exit(int pid, int timeout_ms) {
+#if defined(HAVE_KQUEUE) || defined(HAVE_KQUEUEX)
+    // kqueue monitors the process by its PID, no descriptor is needed.
+    return try_wait_for_exit(-1, pid, timeout_ms);
+#elif defined(HAVE_PIDFD) && defined(SYS_pidfd_open)
+    int pidfd = (int)syscall(SYS_pidfd_open, pid, 0);
+    if (pidfd < 0) {
+        return errno == ESRCH ? 0 : -1;
+    }
+
+    int ret = try_wait_for_exit(pidfd, pid, timeout_ms);
+    int saved_errno = errno;
+    close(pidfd);
+    errno = saved_errno;
+    return ret;
+#else
+    // No kqueue or pidfd support
+    errno = ENOTSUP;
+    return -1;
+#endif
+}
+
+// Returns 0 when the process has exited (it's not reaped) and -1 on error.
+int wait_for_exit_or_kill_on_timeout(int pidfd, int pid, int timeout_ms, int* out_timeout) {
+    *out_timeout = 0;
+    int ret = try_wait_for_exit(pidfd, pid, timeout_ms);
     if (ret != 1) {
         return ret; // Either process exited (0) or error occurred (-1)
     }
@@ -937,7 +1025,7 @@ int wait_for_exit_or_kill_on_timeout(int pidfd, int pid, int timeout_ms, int* ou
         }
     }
 
-    return wait_for_exit_and_reap(pidfd, pid, out_exitCode, out_signal);
+    return wait_for_exit(pidfd, pid);
 }
 
 // Opens an existing process by its process ID.
@@ -970,3 +1058,129 @@ int open_process(int pid, int* out_pidfd) {
 
     return 0;
 }
+
+// Gets the total (user + kernel) CPU time consumed by the process, in nanoseconds.
+// On macOS, proc_pid_rusage is used. On other systems, utime and stime are read from /proc/<pid>/stat.
+// Returns 0 on success, -1 on error (errno is set).
+int get_cpu_time(int pid, uint64_t* out_cpu_time_ns) {
+    *out_cpu_time_ns = 0;
+
+#ifdef HAVE_PROC_PID_RUSAGE
+    struct rusage_info_v2 info;
+    if (proc_pid_rusage(pid, RUSAGE_INFO_V2, (rusage_info_t*)&info) != 0) {
+        return -1;
+    }
+
+    // The times are reported in Mach absolute time units, which are not nanoseconds on Apple Silicon.
+    mach_timebase_info_data_t timebase;
+    if (mach_timebase_info(&timebase) != KERN_SUCCESS) {
+        errno = EINVAL;
+        return -1;
+    }
+
+    *out_cpu_time_ns = (info.ri_user_time + info.ri_system_time) * timebase.numer / timebase.denom;
+    return 0;
+#else
+    char path[64];
+    snprintf(path, sizeof(path), "/proc/%d/stat", pid);
+
+    int fd;
+    while ((fd = open(path, O_RDONLY | O_CLOEXEC)) == -1 && errno == EINTR);
+    if (fd == -1) {
+        return -1;
+    }
+
+    char buffer[1024];
+    ssize_t length;
+    while ((length = read(fd, buffer, sizeof(buffer) - 1)) == -1 && errno == EINTR);
+    int saved_errno = errno;
+    close(fd);
+
+    if (length <= 0) {
+        errno = length == 0 ? EIO : saved_errno;
+        return -1;
+    }
+    buffer[length] = '\0';
+
+    // The process name (2nd field) can contain spaces and parentheses, so we parse the fields after the last ')'.
+    // They are: state ppid pgrp session tty_nr tpgid flags minflt cminflt majflt cmajflt utime stime.
+    const char* fields = strrchr(buffer, ')');
+    unsigned long long utime, stime;
+    if (fields == NULL
+        || sscanf(fields + 1, " %*c %*d %*d %*d %*d %*d %*u %*u %*u %*u %*u %llu %llu", &utime, &stime) != 2) {
+        errno = EINVAL;
+        return -1;
+    }
+
+    long ticks_per_second = sysconf(_SC_CLK_TCK);
+    if (ticks_per_second <= 0) {
+        errno = EINVAL;
+        return -1;
+    }
+
+    *out_cpu_time_ns = (uint64_t)(utime + stime) * 1000000000ULL / (uint64_t)ticks_per_second;
+    return 0;
+#endif
+}
+
+// Gets the nice value of the process.
+// Returns 0 on success, -1 on error (errno is set).
+int get_priority(int pid, int* out_priority) {
+    // -1 is a valid nice value, so errno is the only way to detect a failure.
+    errno = 0;
+    int priority = getpriority(PRIO_PROCESS, (id_t)pid);
+    if (priority == -1 && errno != 0) {
+        return -1;
+    }
+
+    *out_priority = priority;
+    return 0;
+}
+
+// Sets the nice value of the process.
+// Returns 0 on success, -1 on error (errno is set).
+int set_priority(int pid, int priority) {
+    return setpriority(PRIO_PROCESS, (id_t)pid, priority) == -1 ? -1 : 0;
+}
+
+// Waits for the next state change of the process: exit, stop or continue. The state change is not consumed (WNOWAIT).
+// Returns 0 on success, -1 on error (errno is set).
+int wait_for_state_change(int pid) {
+    int ret;
+    siginfo_t info;
+    memset(&info, 0, sizeof(info));
+    while ((ret = waitid(P_PID, pid, &info, WEXITED | WSTOPPED | WCONTINUED | WNOWAIT)) < 0 && errno == EINTR);
+    return ret == -1 ? -1 : 0;
+}
+
+// Consumes the pending state change of the process and returns the unmodified status word from waitpid.
+// An exited process is reaped, its exit code and signal are mapped the same way as by try_get_exit_code.
+// Returns 1 if the process has exited, 0 if it was stopped or continued, 2 if there was no pending state change, -1 on error.
+int try_get_raw_status(int pid, int* out_status, int* out_exitCode, int* out_signal) {
+    int ret;
+    int status = 0;
+    while ((ret = waitpid(pid, &status, WNOHANG | WUNTRACED | WCONTINUED)) < 0 && errno == EINTR);
+
+    if (ret == -1) {
+        return -1;
+    }
+    else if (ret == 0) {
+        return 2;
+    }
+
+    *out_status = status;
+    return map_wait_status(status, out_exitCode, out_signal) == 0 ? 1 : 0;
+}
+
+// Detaches the calling thread from the process it traces and resumes the process.
+// It must be called from the thread that started the process with trace_with_ptrace.
+// Returns 0 on success, -1 on error (errno is set).
+int detach_tracer(int pid) {
+#ifdef HAVE_PTRACE_TRACEME
+    return ptrace(PTRACE_DETACH, pid, NULL, NULL) == -1 ? -1 : 0;
+#else
+    (void)pid;
+    errno = ENOTSUP;
+    return -1;
+#endif
+}
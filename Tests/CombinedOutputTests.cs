@@ -160,6 +160,19 @@ public class CombinedOutputTests
         Assert.True(combinedOutput.ExitStatus.Canceled);
     }
 
+    [Fact]
+    public static void CombinedOutput_WithTimeout_KeepsOutputWrittenBeforeKill()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Write-Output 'before kill'; Start-Sleep 10" } }
+            : new("sh") { Arguments = { "-c", "echo 'before kill'; sleep 10" } };
+
+        CombinedOutput combinedOutput = ChildProcess.CaptureCombined(options, timeout: OperatingSystem.IsWindows() ? TimeSpan.FromSeconds(5) : TimeSpan.FromSeconds(1));
+
+        Assert.True(combinedOutput.ExitStatus.Canceled);
+        Assert.Equal($"before kill{Environment.NewLine}", combinedOutput.GetText());
+    }
+
     [Fact]
     public static async Task CombinedOutputAsync_WithCancellation_ThrowsOperationCanceled()
     {
@@ -143,7 +143,65 @@ public class CreateSuspendedTests
         Assert.Null(exitStatus.Signal);
     }
 
-#if !WINDOWS
+#if WINDOWS
+    [Fact]
+    public static async Task ResumeAfterDebuggerAttach_ProcessDoesNotRunUntilResumed()
+    {
+        string tempFile = Path.Combine(Path.GetTempPath(), $"debugger_test_{Guid.NewGuid()}.txt");
+
+        try
+        {
+            ProcessStartOptions options = new("cmd.exe") { Arguments = { "/c", $"echo test > {tempFile}" } };
+
+            using SafeChildProcessHandle processHandle = SafeChildProcessHandle.StartSuspended(options, input: null, output: null, error: null);
+
+            // No debugger is going to attach, so the process gets resumed when the timeout elapses.
+            Task<bool> resumeTask = Task.Run(() => processHandle.ResumeAfterDebuggerAttach(TimeSpan.FromMilliseconds(500)));
+
+            await Task.Delay(100);
+            Assert.False(resumeTask.IsCompleted, "Should still be waiting for the debugger");
+            Assert.False(File.Exists(tempFile), "File should not exist while process is waiting for the debugger");
+
+            Assert.False(await resumeTask);
+
+            EnsureProcessCompletedSuccessfully(processHandle, TimeSpan.FromSeconds(1));
+            Assert.True(File.Exists(tempFile), "File should exist after process resumed and completed");
+        }
+        finally
+        {
+            if (File.Exists(tempFile))
+            {
+                File.Delete(tempFile);
+            }
+        }
+    }
+
+    [Fact]
+    public static void ResumeAfterDebuggerAttach_ThrowsForProcessThatWasNotStartedSuspended()
+    {
+        ProcessStartOptions options = new("cmd.exe") { Arguments = { "/c", "echo", "test" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        Assert.Throws<InvalidOperationException>(() => processHandle.ResumeAfterDebuggerAttach(TimeSpan.Zero));
+
+        EnsureProcessCompletedSuccessfully(processHandle, TimeSpan.FromSeconds(1));
+    }
+#else
+    [Fact]
+    public static void ResumeAfterDebuggerAttach_Unix_ThrowsPlatformNotSupportedException()
+    {
+        ProcessStartOptions options = new("echo") { Arguments = { "test" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.StartSuspended(options, input: null, output: null, error: null);
+
+        Assert.Throws<PlatformNotSupportedException>(() => processHandle.ResumeAfterDebuggerAttach(TimeSpan.Zero));
+
+        processHandle.Resume();
+
+        EnsureProcessCompletedSuccessfully(processHandle, TimeSpan.FromSeconds(1));
+    }
+
     [Fact]
     public static void StartSuspended_Unix_CanSetProcessPriority()
     {
@@ -0,0 +1,92 @@
+using System;
+using System.ComponentModel;
+using System.IO;
+using System.Threading;
+using System.TBA;
+
+namespace Tests;
+
+// The tests modify PATH of the whole test process and count the resolutions, so they must not run in parallel with any other test.
+[CollectionDefinition(nameof(FileNameResolutionCacheTests), DisableParallelization = true)]
+[Collection(nameof(FileNameResolutionCacheTests))]
+public class FileNameResolutionCacheTests
+{
+#if !WINDOWS
+    [Fact]
+#endif
+    public static void FileNameResolutionCacheTimeToLive_ReusesResolvedPath_UntilItExpires()
+    {
+        string fileName = $"cached_tool_{Guid.NewGuid():N}";
+        string earlierDirectory = Directory.CreateDirectory(Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"))).FullName;
+        string laterDirectory = Directory.CreateDirectory(Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"))).FullName;
+        string? originalPath = Environment.GetEnvironmentVariable("PATH");
+
+        try
+        {
+            Environment.SetEnvironmentVariable("PATH", $"{earlierDirectory}:{laterDirectory}:{originalPath}");
+            CreateExitingScript(laterDirectory, fileName, exitCode: 2);
+
+            ProcessStartOptions options = new(fileName) { FileNameResolutionCacheTimeToLive = TimeSpan.FromMilliseconds(500) };
+            Assert.Equal(2, ChildProcess.Inherit(options).ExitCode);
+            long resolutionCount = ProcessStartOptions.FileNameResolutionCount;
+
+            // An executable that comes earlier in PATH is not looked up while the entry is alive.
+            CreateExitingScript(earlierDirectory, fileName, exitCode: 1);
+            Assert.Equal(2, ChildProcess.Inherit(options).ExitCode);
+            Assert.Equal(resolutionCount, ProcessStartOptions.FileNameResolutionCount);
+            Assert.Equal(1, ChildProcess.Inherit(new ProcessStartOptions(fileName)).ExitCode);
+
+            Thread.Sleep(TimeSpan.FromMilliseconds(600));
+            Assert.Equal(1, ChildProcess.Inherit(options).ExitCode);
+        }
+        finally
+        {
+            Environment.SetEnvironmentVariable("PATH", originalPath);
+            Directory.Delete(earlierDirectory, recursive: true);
+            Directory.Delete(laterDirectory, recursive: true);
+        }
+    }
+
+#if !WINDOWS
+    [Fact]
+#endif
+    public static void FileNameResolutionCacheTimeToLive_ResolvesAgain_WhenCachedExecutableWasRemoved()
+    {
+        string fileName = $"cached_tool_{Guid.NewGuid():N}";
+        string earlierDirectory = Directory.CreateDirectory(Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"))).FullName;
+        string laterDirectory = Directory.CreateDirectory(Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"))).FullName;
+        string? originalPath = Environment.GetEnvironmentVariable("PATH");
+
+        try
+        {
+            Environment.SetEnvironmentVariable("PATH", $"{earlierDirectory}:{laterDirectory}:{originalPath}");
+            string earlierScript = CreateExitingScript(earlierDirectory, fileName, exitCode: 1);
+            CreateExitingScript(laterDirectory, fileName, exitCode: 2);
+
+            ProcessStartOptions options = new(fileName) { FileNameResolutionCacheTimeToLive = TimeSpan.FromMinutes(5) };
+            Assert.Equal(1, ChildProcess.Inherit(options).ExitCode);
+            long resolutionCount = ProcessStartOptions.FileNameResolutionCount;
+
+            // The miss evicts the stale entry, so the next launch finds the other executable.
+            File.Delete(earlierScript);
+            Assert.Throws<Win32Exception>(() => ChildProcess.Inherit(options));
+            Assert.Equal(resolutionCount, ProcessStartOptions.FileNameResolutionCount);
+            Assert.Equal(2, ChildProcess.Inherit(options).ExitCode);
+            Assert.Equal(resolutionCount + 1, ProcessStartOptions.FileNameResolutionCount);
+        }
+        finally
+        {
+            Environment.SetEnvironmentVariable("PATH", originalPath);
+            Directory.Delete(earlierDirectory, recursive: true);
+            Directory.Delete(laterDirectory, recursive: true);
+        }
+    }
+
+    private static string CreateExitingScript(string directory, string fileName, int exitCode)
+    {
+        string script = Path.Combine(directory, fileName);
+        File.WriteAllText(script, $"#!/bin/sh\nexit {exitCode}\n");
+        File.SetUnixFileMode(script, UnixFileMode.UserRead | UnixFileMode.UserWrite | UnixFileMode.UserExecute);
+        return script;
+    }
+}
@@ -199,4 +199,108 @@ public class InheritedHandlesTests
         int exitCode = processHandle.WaitForExit().ExitCode;
         Assert.Equal(0, exitCode);
     }
+
+    [Theory]
+    [InlineData(252)] // 255 with stdio
+    [InlineData(253)] // 256 with stdio: the largest list that is stack allocated on Windows
+    [InlineData(254)] // 257 with stdio
+    [InlineData(255)]
+    [InlineData(256)] // the largest list that is stack allocated on Unix (stdio fds are passed separately)
+    [InlineData(257)]
+    public static void InheritedHandles_AroundStackAllocationThreshold_AllHandlesAreInherited(int count)
+    {
+        string testMessage = "Hello from the last inherited handle!";
+
+        File.CreatePipe(out SafeFileHandle pipeReadHandle, out SafeFileHandle pipeWriteHandle);
+        SafeFileHandle[] nullHandles = new SafeFileHandle[count - 1];
+
+        try
+        {
+            using (FileStream writeStream = new(pipeWriteHandle, FileAccess.Write))
+            {
+                writeStream.Write(Encoding.UTF8.GetBytes(testMessage));
+            }
+
+            ProcessStartOptions options = CreateReadFromHandleOptions(pipeReadHandle.DangerousGetHandle());
+            for (int i = 0; i < nullHandles.Length; i++)
+            {
+                nullHandles[i] = File.OpenNullFileHandle();
+                options.InheritedHandles.Add(nullHandles[i]);
+            }
+            // The pipe goes last, so it's lost if the buffer is under-sized.
+            options.InheritedHandles.Add(pipeReadHandle);
+
+            ProcessOutput output = ChildProcess.CaptureOutput(options);
+
+            Assert.Equal(0, output.ExitStatus.ExitCode);
+            Assert.Equal(testMessage, output.StandardOutput.TrimEnd());
+        }
+        finally
+        {
+            foreach (SafeFileHandle? nullHandle in nullHandles)
+            {
+                nullHandle?.Dispose();
+            }
+            pipeReadHandle.Dispose();
+            pipeWriteHandle.Dispose();
+        }
+    }
+
+    [Fact]
+    public static void InheritedHandles_AreCapturedOnStartAndStayOwnedByCaller()
+    {
+        const string TestMessage = "Written after the parent has closed its copy";
+
+        File.CreatePipe(out SafeFileHandle pipeReadHandle, out SafeFileHandle pipeWriteHandle);
+        File.CreatePipe(out SafeFileHandle outputReadHandle, out SafeFileHandle outputWriteHandle);
+
+        using (pipeWriteHandle)
+        using (outputReadHandle)
+        {
+            ProcessStartOptions options = CreateReadFromHandleOptions(pipeReadHandle.DangerousGetHandle());
+            options.InheritedHandles.Add(pipeReadHandle);
+            // Modifying the list while starting has no effect either, the handles were captured already.
+            options.LaunchAuditCallback = _ =>
+            {
+                options.InheritedHandles.Clear();
+                return true;
+            };
+
+            using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, outputWriteHandle, error: null);
+
+            // The library has not disposed the handle of the caller.
+            Assert.False(pipeReadHandle.IsClosed);
+            Assert.Empty(options.InheritedHandles);
+
+            // Closing it does not affect the copy inherited by the running child.
+            pipeReadHandle.Dispose();
+            Assert.True(pipeReadHandle.IsClosed);
+
+            using (FileStream writeStream = new(pipeWriteHandle, FileAccess.Write, bufferSize: 0))
+            {
+                writeStream.Write(Encoding.UTF8.GetBytes(TestMessage));
+            }
+
+            using StreamReader outputReader = new(new FileStream(outputReadHandle, FileAccess.Read, bufferSize: 0));
+            Assert.Equal(TestMessage, outputReader.ReadToEnd().TrimEnd());
+            Assert.Equal(0, processHandle.WaitForExit().ExitCode);
+        }
+    }
+
+    private static ProcessStartOptions CreateReadFromHandleOptions(IntPtr handleValue)
+    {
+        if (OperatingSystem.IsWindows())
+        {
+            string script = $"""
+            $handle = New-Object Microsoft.Win32.SafeHandles.SafeFileHandle([IntPtr]{(long)handleValue}, $false)
+            $stream = New-Object System.IO.FileStream($handle, [System.IO.FileAccess]::Read)
+            $reader = New-Object System.IO.StreamReader($stream)
+            $reader.ReadToEnd()
+            $reader.Close()
+            """;
+            return new("powershell.exe") { Arguments = { "-NoProfile", "-InputFormat", "None", "-Command", script } };
+        }
+
+        return new("cat") { Arguments = { $"/dev/fd/{(int)handleValue}" } };
+    }
 }
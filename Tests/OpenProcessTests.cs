@@ -51,6 +51,30 @@ public class OpenProcessTests
         }
     }
 
+    [Fact]
+    public void Open_DoesNotKnowStartTime()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-Command", "Start-Sleep 5" } }
+            : new("sleep") { Arguments = { "5" } };
+
+        int processId = ChildProcess.FireAndForget(options);
+
+        using SafeChildProcessHandle handle = SafeChildProcessHandle.Open(processId);
+        try
+        {
+            Assert.Null(handle.StartTime);
+            Assert.Null(handle.Elapsed);
+        }
+        finally
+        {
+            handle.Kill();
+        }
+
+        handle.WaitForExit();
+        Assert.Null(handle.ExitTime);
+    }
+
     [Fact]
     public void Open_CanWaitForExitOnOpenedProcess()
     {
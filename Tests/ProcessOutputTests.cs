@@ -6,6 +6,8 @@ using PosixSignal = System.TBA.PosixSignal;
 using System.Text;
 using System.Diagnostics;
 using System.Linq;
+using System.IO;
+using System.Collections.Generic;
 
 namespace Tests;
 
@@ -132,6 +134,44 @@ public class ProcessOutputTests
         Assert.Equal(0, result.ExitStatus.ExitCode);
     }
 
+    [Theory]
+    [InlineData(1, true)]
+    [InlineData(1, false)]
+    [InlineData(1024 * 1024, true)]
+    [InlineData(1024 * 1024, false)]
+    public static async Task ProcessOutput_CapturesEntireOutput_RegardlessOfReadBufferSize(int readBufferSize, bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "for /L %i in (1,1,1000) do @(echo Line %i& echo Error %i 1>&2)" } }
+            : new("sh") { Arguments = { "-c", "for i in $(seq 1 1000); do echo \"Line $i\"; echo \"Error $i\" >&2; done" } };
+        options.OutputReadBufferSize = readBufferSize;
+
+        ProcessOutput result = useAsync
+            ? await ChildProcess.CaptureOutputAsync(options)
+            : ChildProcess.CaptureOutput(options);
+
+        StringBuilder expectedOutput = new(), expectedError = new();
+        for (int i = 1; i <= 1000; i++)
+        {
+            expectedOutput.AppendLine($"Line {i}");
+            expectedError.AppendLine($"Error {i}");
+        }
+
+        Assert.Equal(expectedOutput.ToString(), result.StandardOutput);
+        Assert.Equal(expectedError.ToString(), result.StandardError);
+        Assert.Equal(0, result.ExitStatus.ExitCode);
+    }
+
+    [Theory]
+    [InlineData(0)]
+    [InlineData(-1)]
+    public static void OutputReadBufferSize_ThrowsForNonPositiveValues(int readBufferSize)
+    {
+        ProcessStartOptions options = new("test_executable");
+
+        Assert.Throws<ArgumentOutOfRangeException>(() => options.OutputReadBufferSize = readBufferSize);
+    }
+
     [Theory]
     [InlineData(true)]
     [InlineData(false)]
@@ -346,4 +386,211 @@ public class ProcessOutputTests
         Assert.Empty(result.StandardError);
         Assert.False(result.ExitStatus.Canceled);
     }
-}
\ No newline at end of file
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task GetExitCodeOrThrowWithDiagnostics_ReturnsZeroForSuccessfulProcess(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "exit 0" } }
+            : new("sh") { Arguments = { "-c", "exit 0" } };
+
+        ProcessOutput result = useAsync
+            ? await ChildProcess.CaptureOutputAsync(options)
+            : ChildProcess.CaptureOutput(options);
+
+        Assert.Equal(0, result.GetExitCodeOrThrowWithDiagnostics());
+    }
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task GetExitCodeOrThrowWithDiagnostics_ThrowsWithDiagnosticsForNonZeroExitCode(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo something went wrong 1>&2 && exit 3" } }
+            : new("sh") { Arguments = { "-c", "echo 'something went wrong' >&2; exit 3" } };
+        options.WorkingDirectory = Path.GetTempPath();
+
+        ProcessOutput result = useAsync
+            ? await ChildProcess.CaptureOutputAsync(options)
+            : ChildProcess.CaptureOutput(options);
+
+        ProcessExitException exception = Assert.Throws<ProcessExitException>(() => result.GetExitCodeOrThrowWithDiagnostics());
+
+        Assert.Equal(3, exception.ExitCode);
+        Assert.Null(exception.Signal);
+        Assert.Same(result.ExitStatus, exception.ExitStatus);
+        Assert.Equal(result.ProcessId, exception.ProcessId);
+        Assert.Equal("something went wrong", exception.StandardErrorTail.Trim());
+        Assert.Equal(OperatingSystem.IsWindows()
+            ? "cmd /c \"echo something went wrong 1>&2 && exit 3\""
+            : "sh -c \"echo 'something went wrong' >&2; exit 3\"", exception.CommandLine);
+        Assert.Equal(Path.GetTempPath(), exception.WorkingDirectory);
+        Assert.True(exception.Elapsed > TimeSpan.Zero);
+    }
+
+    [Fact]
+    public static void GetExitCodeOrThrowWithDiagnostics_IncludesExitCodeDescription()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "exit 23" } }
+            : new("sh") { Arguments = { "-c", "exit 23" } };
+        options.ExitCodeDescriptions = new Dictionary<int, string> { [23] = "partial transfer", [24] = "vanished source files" };
+
+        ProcessOutput result = ChildProcess.CaptureOutput(options);
+
+        ProcessExitException exception = Assert.Throws<ProcessExitException>(() => result.GetExitCodeOrThrowWithDiagnostics());
+
+        Assert.Equal(23, exception.ExitCode);
+        Assert.Equal("partial transfer", exception.ExitCodeDescription);
+        Assert.Contains("exited with code 23 (partial transfer)", exception.Message);
+    }
+
+    [Fact]
+    public static void GetExitCodeOrThrowWithDiagnostics_HasNoDescriptionForUnknownExitCode()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "exit 3" } }
+            : new("sh") { Arguments = { "-c", "exit 3" } };
+        options.ExitCodeDescriptions = new Dictionary<int, string> { [23] = "partial transfer" };
+
+        ProcessExitException exception = Assert.Throws<ProcessExitException>(() => ChildProcess.CaptureOutput(options).GetExitCodeOrThrowWithDiagnostics());
+
+        Assert.Null(exception.ExitCodeDescription);
+        Assert.Contains("exited with code 3 after", exception.Message);
+    }
+
+    [Fact]
+    public static void GetExitCodeOrThrowWithDiagnostics_IncludesOutputCapturedBeforeTimeoutKill()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Write-Output 'started'; [Console]::Error.WriteLine('diagnostic 1'); [Console]::Error.WriteLine('diagnostic 2'); Start-Sleep 10" } }
+            : new("sh") { Arguments = { "-c", "echo started; echo 'diagnostic 1' >&2; echo 'diagnostic 2' >&2; sleep 10" } };
+
+        // PowerShell needs a while to start.
+        ProcessOutput result = ChildProcess.CaptureOutput(options, timeout: OperatingSystem.IsWindows() ? TimeSpan.FromSeconds(5) : TimeSpan.FromSeconds(1));
+
+        Assert.True(result.ExitStatus.Canceled);
+        Assert.Equal($"started{Environment.NewLine}", result.StandardOutput);
+
+        ProcessExitException exception = Assert.Throws<ProcessExitException>(() => result.GetExitCodeOrThrowWithDiagnostics());
+
+        Assert.Equal($"diagnostic 1{Environment.NewLine}diagnostic 2{Environment.NewLine}", exception.StandardErrorTail);
+        Assert.Contains("was killed on timeout or cancellation", exception.Message);
+        Assert.Contains("diagnostic 2", exception.Message);
+    }
+
+    [Fact(Skip = ConditionalTests.UnixOnly)]
+    public static void GetExitCodeOrThrowWithDiagnostics_ThrowsForProcessTerminatedBySignal()
+    {
+        ProcessStartOptions options = new("sh") { Arguments = { "-c", "kill -KILL $$" } };
+
+        ProcessOutput result = ChildProcess.CaptureOutput(options);
+
+        ProcessExitException exception = Assert.Throws<ProcessExitException>(() => result.GetExitCodeOrThrowWithDiagnostics());
+
+        Assert.Equal(PosixSignal.SIGKILL, exception.Signal);
+        Assert.Equal(result.ExitStatus.ExitCode, exception.ExitCode);
+        Assert.Equal(string.Empty, exception.StandardErrorTail);
+    }
+
+    [Fact]
+    public static void GetExitCodeOrThrowWithDiagnostics_ThrowsForDefaultInstance()
+    {
+        Assert.Throws<InvalidOperationException>(() => default(ProcessOutput).GetExitCodeOrThrowWithDiagnostics());
+    }
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task CaptureSeekableOutput_SpillsLargeOutputToFile_AndCanBeReadTwice(bool useAsync)
+    {
+        const int LineCount = 200_000; // more than 1 MB
+
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", $"1..{LineCount}" } }
+            : new("seq") { Arguments = { "1", $"{LineCount}" } };
+        options.CapturedOutputSpillThresholdBytes = 64 * 1024;
+
+        using SeekableProcessOutput result = useAsync
+            ? await ChildProcess.CaptureSeekableOutputAsync(options)
+            : ChildProcess.CaptureSeekableOutput(options);
+
+        StringBuilder expected = new();
+        for (int i = 1; i <= LineCount; i++)
+        {
+            expected.Append(i).Append(Environment.NewLine);
+        }
+
+        Assert.Equal(0, result.ExitStatus.ExitCode);
+        Assert.True(result.IsSpilledToFile);
+        Assert.True(result.StandardOutput.CanSeek);
+
+        string first = ReadToEnd(result.StandardOutput);
+        result.StandardOutput.Position = 0;
+        string second = ReadToEnd(result.StandardOutput);
+
+        Assert.Equal(expected.ToString(), first);
+        Assert.Equal(first, second);
+
+        string path = ((FileStream)result.StandardOutput).Name;
+        result.Dispose();
+        Assert.False(File.Exists(path));
+
+        static string ReadToEnd(Stream stream)
+        {
+            using StreamReader reader = new(stream, Encoding.UTF8, detectEncodingFromByteOrderMarks: false, leaveOpen: true);
+            return reader.ReadToEnd();
+        }
+    }
+
+    [Fact]
+    public static void CaptureSeekableOutput_KeepsOutputBelowThresholdInMemory()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo hello" } }
+            : new("sh") { Arguments = { "-c", "echo hello" } };
+        options.CapturedOutputSpillThresholdBytes = 1024;
+
+        using SeekableProcessOutput result = ChildProcess.CaptureSeekableOutput(options);
+
+        Assert.False(result.IsSpilledToFile);
+        Assert.Equal(0, result.StandardOutput.Position);
+        Assert.Equal(Encoding.UTF8.GetBytes("hello" + Environment.NewLine), ((MemoryStream)result.StandardOutput).ToArray());
+        Assert.Throws<ArgumentOutOfRangeException>(() => options.CapturedOutputSpillThresholdBytes = -1);
+    }
+
+    [Theory]
+    [InlineData(false)]
+    [InlineData(true)]
+    public static async Task CaptureSeekableOutput_CapturesStandardError(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo out&& echo err 1>&2" } }
+            : new("sh") { Arguments = { "-c", "echo out; echo err >&2" } };
+
+        using SeekableProcessOutput result = useAsync
+            ? await ChildProcess.CaptureSeekableOutputAsync(options)
+            : ChildProcess.CaptureSeekableOutput(options);
+
+        Assert.Equal(0, result.ExitStatus.ExitCode);
+        Assert.Equal(Encoding.UTF8.GetBytes("out" + Environment.NewLine), ((MemoryStream)result.StandardOutput).ToArray());
+        Assert.Equal(Encoding.UTF8.GetBytes((OperatingSystem.IsWindows() ? "err " : "err") + Environment.NewLine), ((MemoryStream)result.StandardError).ToArray());
+    }
+
+    [Fact]
+    public static void CaptureSeekableOutput_WithTimeout_KeepsOutputWrittenBeforeKill()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Write-Output 'before kill'; Start-Sleep 10" } }
+            : new("sh") { Arguments = { "-c", "echo 'before kill'; sleep 10" } };
+
+        using SeekableProcessOutput result = ChildProcess.CaptureSeekableOutput(options,
+            timeout: OperatingSystem.IsWindows() ? TimeSpan.FromSeconds(5) : TimeSpan.FromSeconds(1));
+
+        Assert.True(result.ExitStatus.Canceled);
+        Assert.Equal(Encoding.UTF8.GetBytes("before kill" + Environment.NewLine), ((MemoryStream)result.StandardOutput).ToArray());
+    }
+}
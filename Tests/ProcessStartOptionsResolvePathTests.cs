@@ -1,4 +1,5 @@
 using System;
+using System.ComponentModel;
 using System.IO;
 using System.TBA;
 using PosixSignal = System.TBA.PosixSignal;
@@ -164,6 +165,75 @@ public class ProcessStartOptionsResolvePathTests
         }
     }
 
+#if !WINDOWS
+    [Fact]
+#endif
+    public static void FileName_IsResolvedWithParentPath_WhenChildEnvironmentIsCleared()
+    {
+        ProcessStartOptions options = new("env");
+        options.Environment.Clear();
+
+        ProcessOutput output = ChildProcess.CaptureOutput(options);
+
+        // "env" was found, but the child did not get PATH (or anything else).
+        Assert.Equal(0, output.ExitStatus.ExitCode);
+        Assert.Empty(output.StandardOutput);
+    }
+
+    [Fact]
+    public static void PreResolvedExecutable_IsTrueForResolvedPath()
+    {
+        ProcessStartOptions options = ProcessStartOptions.ResolvePath(OperatingSystem.IsWindows() ? "cmd" : "sh");
+
+        Assert.True(options.PreResolvedExecutable);
+        Assert.False(new ProcessStartOptions(options.FileName).PreResolvedExecutable);
+    }
+
+    [Fact]
+    public static void PreResolvedExecutable_RequiresFullyQualifiedPath()
+    {
+        ProcessStartOptions options = new(OperatingSystem.IsWindows() ? "cmd" : "sh");
+
+        Assert.Throws<ArgumentException>(() => options.PreResolvedExecutable = true);
+        Assert.False(options.PreResolvedExecutable);
+
+        // It can always be unset.
+        options.PreResolvedExecutable = false;
+    }
+
+    [Fact]
+    public static void PreResolvedExecutable_StartsWithoutLookup()
+    {
+        ProcessStartOptions options = new(ProcessStartOptions.ResolvePath(OperatingSystem.IsWindows() ? "cmd" : "sh").FileName)
+        {
+            Arguments = { OperatingSystem.IsWindows() ? "/c" : "-c", "exit 3" },
+            PreResolvedExecutable = true,
+        };
+
+        Assert.Equal(3, ChildProcess.Discard(options).ExitCode);
+    }
+
+    [Fact]
+    public static void PreResolvedExecutable_MissingPath_FailsAtSpawnWithClearError()
+    {
+        string missingPath = Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"), "missing");
+        ProcessStartOptions options = new(missingPath) { PreResolvedExecutable = true };
+
+        Win32Exception exception = Assert.Throws<Win32Exception>(() => ChildProcess.Discard(options));
+
+        Assert.Equal(2, exception.NativeErrorCode); // ENOENT and ERROR_FILE_NOT_FOUND
+        Assert.Equal($"The pre-resolved executable '{missingPath}' could not be found.", exception.Message);
+    }
+
+    [Fact]
+    public static void FileNameResolutionCacheTimeToLive_ThrowsForNonPositiveValue()
+    {
+        ProcessStartOptions options = new("test_executable");
+
+        Assert.Throws<ArgumentOutOfRangeException>(() => options.FileNameResolutionCacheTimeToLive = TimeSpan.Zero);
+        Assert.Throws<ArgumentOutOfRangeException>(() => options.FileNameResolutionCacheTimeToLive = TimeSpan.FromSeconds(-1));
+    }
+
     private static string? GetExecutablePath()
     {
         return System.Environment.ProcessPath;
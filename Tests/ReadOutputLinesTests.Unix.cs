@@ -0,0 +1,104 @@
+using System;
+using System.IO;
+using System.Linq;
+using System.Runtime.InteropServices;
+using System.Text;
+using System.TBA;
+using Microsoft.Win32.SafeHandles;
+
+namespace Tests;
+
+public partial class ReadOutputLinesTests
+{
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static void StartWithNulStdinToAvoidTerminalSteal_ChildCanNotReadParentTerminal(bool safeMode)
+    {
+        // Simulate an interactive parent: STD IN is a pseudo-terminal with a pending line typed by the user.
+        using SafeFileHandle master = new(posix_openpt(O_RDWR), ownsHandle: true);
+        Assert.False(master.IsInvalid);
+        Assert.Equal(0, grantpt((int)master.DangerousGetHandle()));
+        Assert.Equal(0, unlockpt((int)master.DangerousGetHandle()));
+        string slavePath = Marshal.PtrToStringUTF8(ptsname((int)master.DangerousGetHandle()))!;
+
+        using SafeFileHandle slave = File.OpenHandle(slavePath, FileMode.Open, FileAccess.ReadWrite);
+        // The master must stay open, closing it hangs up the terminal.
+        byte[] keystrokes = Encoding.ASCII.GetBytes("secret\n");
+        Assert.Equal(keystrokes.Length, (int)write((int)master.DangerousGetHandle(), keystrokes, keystrokes.Length));
+
+        string[] output = ReadLinesWithStandardInput(slave, safeMode);
+
+        Assert.Equal(new[] { safeMode ? "[]" : "[secret]" }, output);
+    }
+
+    [Fact]
+    public static void StartWithNulStdinToAvoidTerminalSteal_DoesNotAffectRedirectedStandardInput()
+    {
+        File.CreatePipe(out SafeFileHandle readPipe, out SafeFileHandle writePipe);
+
+        using (readPipe)
+        {
+            using (FileStream writeStream = new(writePipe, FileAccess.Write, bufferSize: 0))
+            {
+                writeStream.Write("piped\n"u8);
+            }
+
+            string[] output = ReadLinesWithStandardInput(readPipe, safeMode: true);
+
+            Assert.Equal(new[] { "[piped]" }, output);
+        }
+    }
+
+    private static string[] ReadLinesWithStandardInput(SafeFileHandle standardInput, bool safeMode)
+    {
+        ProcessStartOptions options = new("sh")
+        {
+            Arguments = { "-c", "read -r line; echo \"[$line]\"" },
+            StartWithNulStdinToAvoidTerminalSteal = safeMode
+        };
+
+        // Temporarily replace STD IN of the test process, the child gets it through StreamOutputLines.
+        int savedStandardInput = dup(0);
+        Assert.True(savedStandardInput >= 0);
+        try
+        {
+            Assert.Equal(0, dup2((int)standardInput.DangerousGetHandle(), 0));
+
+            return ChildProcess.StreamOutputLines(options, TimeSpan.FromSeconds(5))
+                .Select(line => line.Content)
+                .ToArray();
+        }
+        finally
+        {
+            dup2(savedStandardInput, 0);
+            close(savedStandardInput);
+        }
+    }
+
+    private const int O_RDWR = 2;
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial int posix_openpt(int flags);
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial int grantpt(int fd);
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial int unlockpt(int fd);
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial IntPtr ptsname(int fd);
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial nint write(int fd, byte[] buffer, nint count);
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial int dup(int fd);
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial int dup2(int oldfd, int newfd);
+
+    [LibraryImport("libc", SetLastError = true)]
+    private static partial int close(int fd);
+}
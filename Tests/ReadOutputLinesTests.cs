@@ -8,10 +8,11 @@ using System.Threading.Tasks;
 using System.TBA;
 using PosixSignal = System.TBA.PosixSignal;
 using System.Diagnostics;
+using Microsoft.Win32.SafeHandles;
 
 namespace Tests;
 
-public class ReadOutputLinesTests
+public partial class ReadOutputLinesTests
 {
     [Theory]
     [InlineData(true)]
@@ -148,6 +149,53 @@ public class ReadOutputLinesTests
         Assert.All(lines, line => Assert.False(line.StandardError));
     }
 
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task ReadOutputLines_CountsBytesReadFromBothStreams(bool useAsync)
+    {
+        // 1000 lines of "Line N" on standard output and 100 lines on standard error.
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "(for /L %i in (1,1,1000) do @echo Line %i) && (for /L %i in (1,1,100) do @echo E 1>&2)" } }
+            : new("sh") { Arguments = { "-c", "for i in $(seq 1 1000); do echo \"Line $i\"; done; for i in $(seq 1 100); do echo E >&2; done" } };
+        int newLineLength = OperatingSystem.IsWindows() ? 2 : 1;
+
+        ProcessOutputLines outputLines = ChildProcess.StreamOutputLines(options);
+        long expectedOutputBytes = 0, expectedErrorBytes = 0;
+        void Count(ProcessOutputLine line)
+        {
+            int byteCount = Encoding.UTF8.GetByteCount(line.Content) + newLineLength;
+            if (line.StandardError)
+            {
+                expectedErrorBytes += byteCount;
+            }
+            else
+            {
+                expectedOutputBytes += byteCount;
+            }
+        }
+
+        if (useAsync)
+        {
+            await foreach (var line in outputLines)
+            {
+                Count(line);
+            }
+        }
+        else
+        {
+            foreach (var line in outputLines)
+            {
+                Count(line);
+            }
+        }
+
+        // "Line " is 5 bytes and the numbers from 1 to 1000 have 2893 digits in total.
+        Assert.Equal(1000 * (5 + newLineLength) + 2893, outputLines.StandardOutputBytesRead);
+        Assert.Equal(expectedOutputBytes, outputLines.StandardOutputBytesRead);
+        Assert.Equal(expectedErrorBytes, outputLines.StandardErrorBytesRead);
+    }
+
     [Theory]
     [InlineData(true)]
     [InlineData(false)]
@@ -451,4 +499,202 @@ public class ReadOutputLinesTests
 
         Assert.Equal(10, lineCount);
     }
-}
\ No newline at end of file
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task ReadOutputLines_StandardStreamsUnbuffered_DeliversOutputWithoutWaitingForNewLine(bool useAsync)
+    {
+        // The child emits the next chunk only after the parent has signaled (by creating a file) that it received the previous one,
+        // so the test would time out if any chunk was held back until a new line or the end of the output.
+        string signalPath = Path.Combine(Path.GetTempPath(), $"unbuffered_test_{Guid.NewGuid()}");
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command",
+                $"[Console]::Out.Write('a'); while (-not (Test-Path '{signalPath}.a')) {{ Start-Sleep -Milliseconds 10 }}; " +
+                $"[Console]::Out.Write('b'); while (-not (Test-Path '{signalPath}.b')) {{ Start-Sleep -Milliseconds 10 }}; [Console]::Out.Write('c')" } }
+            : new("sh") { Arguments = { "-c",
+                "printf a; until [ -e \"$1.a\" ]; do sleep 0.01; done; printf b; until [ -e \"$1.b\" ]; do sleep 0.01; done; printf c",
+                "sh", signalPath } };
+        options.StandardStreamsUnbuffered = true;
+
+        List<string> chunks = [];
+
+        try
+        {
+            if (useAsync)
+            {
+                await foreach (var line in ChildProcess.StreamOutputLines(options, TimeSpan.FromSeconds(5)))
+                {
+                    chunks.Add(line.Content);
+                    File.Create($"{signalPath}.{line.Content}").Dispose();
+                }
+            }
+            else
+            {
+                foreach (var line in ChildProcess.StreamOutputLines(options, TimeSpan.FromSeconds(5)))
+                {
+                    chunks.Add(line.Content);
+                    File.Create($"{signalPath}.{line.Content}").Dispose();
+                }
+            }
+        }
+        finally
+        {
+            File.Delete($"{signalPath}.a");
+            File.Delete($"{signalPath}.b");
+            File.Delete($"{signalPath}.c");
+        }
+
+        Assert.Equal(["a", "b", "c"], chunks);
+    }
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task ReadOutputLines_ByDefault_DeliversOutputWithoutNewLineAsSingleLine(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "[Console]::Out.Write('a'); [Console]::Out.Write('b'); [Console]::Out.Write('c')" } }
+            : new("sh") { Arguments = { "-c", "printf a; printf b; printf c" } };
+
+        List<string> chunks = [];
+
+        if (useAsync)
+        {
+            await foreach (var line in ChildProcess.StreamOutputLines(options))
+            {
+                chunks.Add(line.Content);
+            }
+        }
+        else
+        {
+            foreach (var line in ChildProcess.StreamOutputLines(options))
+            {
+                chunks.Add(line.Content);
+            }
+        }
+
+        Assert.Equal(["abc"], chunks);
+    }
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task ReadOutputLines_StandardStreamsUnbuffered_DeliversLargeOutputInSmallChunks(bool useAsync)
+    {
+        const int OutputLength = 1000;
+
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", $"[Console]::Out.Write('x' * {OutputLength})" } }
+            : new("sh") { Arguments = { "-c", $"head -c {OutputLength} /dev/zero | tr '\\0' x" } };
+        options.StandardStreamsUnbuffered = true;
+
+        List<string> chunks = [];
+
+        if (useAsync)
+        {
+            await foreach (var line in ChildProcess.StreamOutputLines(options))
+            {
+                chunks.Add(line.Content);
+            }
+        }
+        else
+        {
+            foreach (var line in ChildProcess.StreamOutputLines(options))
+            {
+                chunks.Add(line.Content);
+            }
+        }
+
+        // The reads are small, so no chunk is longer than 128 characters.
+        Assert.All(chunks, chunk => Assert.InRange(chunk.Length, 1, 128));
+        Assert.Equal(new string('x', OutputLength), string.Concat(chunks));
+    }
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task ReadOutputLines_StandardStreamsUnbuffered_PreservesNewLines(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo first&& echo second" } }
+            : new("sh") { Arguments = { "-c", "echo first && echo second" } };
+        options.StandardStreamsUnbuffered = true;
+
+        StringBuilder output = new();
+
+        if (useAsync)
+        {
+            await foreach (var line in ChildProcess.StreamOutputLines(options))
+            {
+                output.Append(line.Content);
+            }
+        }
+        else
+        {
+            foreach (var line in ChildProcess.StreamOutputLines(options))
+            {
+                output.Append(line.Content);
+            }
+        }
+
+        Assert.Equal($"first{Environment.NewLine}second{Environment.NewLine}", output.ToString());
+    }
+
+    [Fact]
+    public static async Task WaitForOutputIdleAsync_ReturnsTrueWhenProcessStalls()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "'started'; Start-Sleep 10" } }
+            : new("sh") { Arguments = { "-c", "echo started; exec sleep 10" } };
+
+        ProcessOutputLines lines = ChildProcess.StreamOutputLines(options);
+        await using IAsyncEnumerator<ProcessOutputLine> enumerator = lines.GetAsyncEnumerator();
+
+        Assert.True(await enumerator.MoveNextAsync());
+        Assert.Equal("started", enumerator.Current.Content);
+
+        // Keep consuming the output while the watchdog is waiting.
+        Task<bool> moveNext = enumerator.MoveNextAsync().AsTask();
+        Task<bool> idle = lines.WaitForOutputIdleAsync(TimeSpan.FromMilliseconds(500));
+
+        Assert.True(await idle.WaitAsync(TimeSpan.FromSeconds(5)));
+        Assert.False(moveNext.IsCompleted);
+
+        using (SafeChildProcessHandle processHandle = SafeChildProcessHandle.Open(lines.ProcessId))
+        {
+            processHandle.Kill();
+        }
+
+        Assert.False(await moveNext);
+    }
+
+    [Fact]
+    public static async Task WaitForOutputIdleAsync_ReturnsFalseWhenProcessExitsWhileProducingOutput()
+    {
+        // The child prints every 100ms, so the idle timer is reset before it elapses, and then it exits.
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "1..15 | ForEach-Object { 'tick'; Start-Sleep -Milliseconds 100 }" } }
+            : new("sh") { Arguments = { "-c", "i=0; while [ $i -lt 15 ]; do echo tick; sleep 0.1; i=$((i+1)); done" } };
+
+        ProcessOutputLines lines = ChildProcess.StreamOutputLines(options);
+        Task<bool>? idle = null;
+
+        await foreach (ProcessOutputLine line in lines)
+        {
+            idle ??= lines.WaitForOutputIdleAsync(TimeSpan.FromSeconds(1));
+        }
+
+        Assert.NotNull(idle);
+        Assert.False(await idle.WaitAsync(TimeSpan.FromSeconds(5)));
+        Assert.Equal(0, lines.ExitStatus.ExitCode);
+    }
+
+    [Fact]
+    public static async Task WaitForOutputIdleAsync_ThrowsWhenProcessHasNotStarted()
+    {
+        ProcessOutputLines lines = ChildProcess.StreamOutputLines(new("sh"));
+
+        await Assert.ThrowsAsync<InvalidOperationException>(() => lines.WaitForOutputIdleAsync(TimeSpan.FromSeconds(1)));
+    }
+}
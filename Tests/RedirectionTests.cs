@@ -1,5 +1,7 @@
 using System.IO;
 using System;
+using System.Collections.Generic;
+using System.Linq;
 using System.Threading.Tasks;
 using System.TBA;
 using PosixSignal = System.TBA.PosixSignal;
@@ -33,4 +35,247 @@ public class RedirectionTests
         Assert.NotEmpty(allOutput);
         Assert.NotEqual(0, exitStatus.ExitCode);
     }
+
+    [Theory]
+    [InlineData(false)]
+    [InlineData(true)]
+    public static async Task RedirectToFiles_WithRotation_RotatesOutputFile(bool useAsync)
+    {
+        const int MaxFileSize = 1000, MaxRotatedFiles = 2;
+
+        // 100 lines, 50 bytes each (51 on Windows), which is enough to fill more than MaxRotatedFiles + 1 files.
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "1..100 | ForEach-Object { '0123456789012345678901234567890123456789012345678' }" } }
+            : new("sh") { Arguments = { "-c", "i=0; while [ $i -lt 100 ]; do echo 0123456789012345678901234567890123456789012345678; i=$((i+1)); done" } };
+        options.StandardOutputToFileWithRotation = new(MaxFileSize, MaxRotatedFiles);
+
+        string directory = Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"));
+        Directory.CreateDirectory(directory);
+        string outputFile = Path.Combine(directory, "output.log");
+
+        try
+        {
+            ProcessExitStatus exitStatus = useAsync
+                ? await ChildProcess.RedirectToFilesAsync(options, inputFile: null, outputFile, errorFile: null)
+                : ChildProcess.RedirectToFiles(options, inputFile: null, outputFile, errorFile: null);
+
+            Assert.Equal(0, exitStatus.ExitCode);
+            Assert.Equal(MaxFileSize, new FileInfo($"{outputFile}.1").Length);
+            Assert.Equal(MaxFileSize, new FileInfo($"{outputFile}.2").Length);
+            Assert.False(File.Exists($"{outputFile}.3"));
+
+            string current = File.ReadAllText(outputFile);
+            Assert.InRange(current.Length, 1, MaxFileSize);
+            Assert.EndsWith("0123456789012345678901234567890123456789012345678" + Environment.NewLine, current);
+        }
+        finally
+        {
+            Directory.Delete(directory, recursive: true);
+        }
+    }
+
+    [Fact]
+    public static void RedirectToFiles_WithRotation_OutputFileIsAppended()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo second" } }
+            : new("sh") { Arguments = { "-c", "echo second" } };
+        options.StandardOutputToFileWithRotation = new(maxFileSize: 1024, maxRotatedFiles: 1);
+
+        string outputFile = Path.GetTempFileName();
+
+        try
+        {
+            File.WriteAllText(outputFile, "first" + Environment.NewLine);
+
+            ProcessExitStatus exitStatus = ChildProcess.RedirectToFiles(options, inputFile: null, outputFile, errorFile: null);
+
+            Assert.Equal(0, exitStatus.ExitCode);
+            Assert.Equal($"first{Environment.NewLine}second{Environment.NewLine}", File.ReadAllText(outputFile));
+            Assert.False(File.Exists($"{outputFile}.1"));
+        }
+        finally
+        {
+            File.Delete(outputFile);
+        }
+    }
+
+    [Fact]
+    public static void RedirectToFiles_WithRotation_KeepsOutputWrittenBeforeTimeout()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Write-Output 'before kill'; Start-Sleep 10" } }
+            : new("sh") { Arguments = { "-c", "echo 'before kill'; sleep 10" } };
+        options.StandardOutputToFileWithRotation = new(maxFileSize: 1024, maxRotatedFiles: 1);
+
+        string outputFile = Path.GetTempFileName();
+
+        try
+        {
+            ProcessExitStatus exitStatus = ChildProcess.RedirectToFiles(options, inputFile: null, outputFile, errorFile: null,
+                timeout: OperatingSystem.IsWindows() ? TimeSpan.FromSeconds(5) : TimeSpan.FromSeconds(1));
+
+            Assert.True(exitStatus.Canceled);
+            Assert.Equal($"before kill{Environment.NewLine}", File.ReadAllText(outputFile));
+        }
+        finally
+        {
+            File.Delete(outputFile);
+        }
+    }
+
+    [Theory]
+    [InlineData(false)]
+    [InlineData(true)]
+    public static async Task StreamOutput_SeesAllBytesInOrder(bool useAsync)
+    {
+        const int LineCount = 20_000; // way more than a single read can return
+
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", $"1..{LineCount}" } }
+            : new("seq") { Arguments = { "1", $"{LineCount}" } };
+
+        MemoryStream received = new();
+        int chunks = 0;
+        SpanConsumer consumer = chunk =>
+        {
+            chunks++;
+            received.Write(chunk);
+        };
+
+        ProcessExitStatus exitStatus = useAsync
+            ? await ChildProcess.StreamOutputAsync(options, consumer)
+            : ChildProcess.StreamOutput(options, consumer);
+
+        StringBuilder expected = new();
+        for (int i = 1; i <= LineCount; i++)
+        {
+            expected.Append(i).Append(Environment.NewLine);
+        }
+
+        Assert.Equal(0, exitStatus.ExitCode);
+        Assert.True(chunks > 1);
+        Assert.Equal(expected.ToString(), Encoding.UTF8.GetString(received.ToArray()));
+    }
+
+    [Theory]
+    [InlineData(false)]
+    [InlineData(true)]
+    public static async Task StreamOutput_RethrowsConsumerException(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo hello" } }
+            : new("sh") { Arguments = { "-c", "echo hello" } };
+        SpanConsumer consumer = _ => throw new FormatException("Unexpected output.");
+
+        FormatException exception = useAsync
+            ? await Assert.ThrowsAsync<FormatException>(() => ChildProcess.StreamOutputAsync(options, consumer))
+            : Assert.Throws<FormatException>(() => ChildProcess.StreamOutput(options, consumer));
+        Assert.Equal("Unexpected output.", exception.Message);
+    }
+
+    [Fact]
+    public static void StreamOutput_InvokesConsumerOnCallingThread()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo hello" } }
+            : new("sh") { Arguments = { "-c", "echo hello" } };
+
+        int callingThreadId = Environment.CurrentManagedThreadId;
+        List<int> consumerThreadIds = new();
+
+        ProcessExitStatus exitStatus = ChildProcess.StreamOutput(options, _ => consumerThreadIds.Add(Environment.CurrentManagedThreadId));
+
+        Assert.Equal(0, exitStatus.ExitCode);
+        Assert.NotEmpty(consumerThreadIds);
+        Assert.All(consumerThreadIds, threadId => Assert.Equal(callingThreadId, threadId));
+    }
+
+    [Fact]
+    public static void StreamOutput_WithTimeout_HandsOverOutputWrittenBeforeKill()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Write-Output 'before kill'; Start-Sleep 10" } }
+            : new("sh") { Arguments = { "-c", "echo 'before kill'; sleep 10" } };
+
+        MemoryStream received = new();
+        ProcessExitStatus exitStatus = ChildProcess.StreamOutput(options, chunk => received.Write(chunk),
+            timeout: OperatingSystem.IsWindows() ? TimeSpan.FromSeconds(5) : TimeSpan.FromSeconds(1));
+
+        Assert.True(exitStatus.Canceled);
+        Assert.Equal($"before kill{Environment.NewLine}", Encoding.UTF8.GetString(received.ToArray()));
+    }
+
+    [Theory]
+    [InlineData(false)]
+    [InlineData(true)]
+    public static async Task RedirectToTextWriters_WritesDecodedTextWithNormalizedLineEndings(bool useAsync)
+    {
+        // cmd writes \r\n, the printf on Unix a mix of \n and \r\n and a non-ASCII character.
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo first&& echo second&& echo error 1>&2" } }
+            : new("sh") { Arguments = { "-c", "printf 'first\\r\\nsec\\303\\266nd\\nno new line'; echo error >&2" } };
+
+        StringWriter output = new() { NewLine = "\n" };
+        StringWriter error = new() { NewLine = "<EOL>" };
+
+        ProcessExitStatus exitStatus = useAsync
+            ? await ChildProcess.RedirectToTextWritersAsync(options, output, error)
+            : ChildProcess.RedirectToTextWriters(options, output, error);
+
+        Assert.Equal(0, exitStatus.ExitCode);
+        Assert.Equal(OperatingSystem.IsWindows() ? "first\nsecond\n" : "first\nsec\u00F6nd\nno new line", output.ToString());
+        Assert.Equal(OperatingSystem.IsWindows() ? "error <EOL>" : "error<EOL>", error.ToString());
+    }
+
+    [Theory]
+    [InlineData(false)]
+    [InlineData(true)]
+    public static async Task RedirectToTextWriters_WithSameWriterForOutputAndError_PreservesTheOrder(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo out&& echo err 1>&2&& echo out again" } }
+            : new("sh") { Arguments = { "-c", "echo out; echo err >&2; echo out again" } };
+
+        StringWriter writer = new() { NewLine = "\n" };
+
+        ProcessExitStatus exitStatus = useAsync
+            ? await ChildProcess.RedirectToTextWritersAsync(options, writer, writer)
+            : ChildProcess.RedirectToTextWriters(options, writer, writer);
+
+        Assert.Equal(0, exitStatus.ExitCode);
+        string[] lines = writer.ToString().Split('\n', StringSplitOptions.RemoveEmptyEntries | StringSplitOptions.TrimEntries);
+        Assert.Equal(["out", "err", "out again"], lines);
+    }
+
+    [Fact]
+    public static void RedirectToTextWriters_WithOnlyErrorWriter_DiscardsOutput()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd") { Arguments = { "/c", "echo out&& echo err 1>&2" } }
+            : new("sh") { Arguments = { "-c", "echo out; echo err >&2" } };
+
+        StringWriter error = new() { NewLine = "\n" };
+
+        Assert.Equal(0, ChildProcess.RedirectToTextWriters(options, outputWriter: null, error).ExitCode);
+        Assert.Equal(OperatingSystem.IsWindows() ? "err \n" : "err\n", error.ToString());
+    }
+
+    [Fact]
+    public static void RedirectToTextWriters_WithTimeout_WritesOutputWrittenBeforeKill()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Write-Output 'before kill'; [Console]::Error.WriteLine('error'); Start-Sleep 10" } }
+            : new("sh") { Arguments = { "-c", "echo 'before kill'; echo error >&2; sleep 10" } };
+
+        StringWriter output = new() { NewLine = "\n" };
+        StringWriter error = new() { NewLine = "\n" };
+
+        ProcessExitStatus exitStatus = ChildProcess.RedirectToTextWriters(options, output, error,
+            timeout: OperatingSystem.IsWindows() ? TimeSpan.FromSeconds(5) : TimeSpan.FromSeconds(1));
+
+        Assert.True(exitStatus.Canceled);
+        Assert.Equal("before kill\n", output.ToString());
+        Assert.Equal("error\n", error.ToString());
+    }
 }
@@ -1,6 +1,8 @@
 using System;
+using System.Collections.Generic;
 using System.ComponentModel;
 using System.IO;
+using System.Linq;
 using System.TBA;
 using PosixSignal = System.TBA.PosixSignal;
 using System.Threading;
@@ -31,6 +33,21 @@ public partial class SafeChildProcessHandleTests
         Assert.True(exitStatus.ExitCode > 128, $"Exit code {exitStatus.ExitCode} should indicate signal termination (>128)");
     }
 
+    [Fact]
+    public async Task WaitForExitAsync_ReportsReasonConsistentWithCodeAndSignal_OfSignaledChild()
+    {
+        // The child kills itself, so nothing but the single wait result tells how it ended.
+        ProcessStartOptions options = new("sh") { Arguments = { "-c", "kill -TERM $$" } };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        ProcessExitStatus exitStatus = await processHandle.WaitForExitAsync();
+
+        Assert.Equal(ProcessExitReason.Signaled, exitStatus.Reason);
+        Assert.Equal(PosixSignal.SIGTERM, exitStatus.Signal);
+        Assert.Equal(128 + 15, exitStatus.ExitCode); // SIGTERM is 15 on all Unixes we support
+        Assert.False(exitStatus.Canceled);
+    }
+
     [Fact]
     public void SendSignal_SIGINT_TerminatesProcess()
     {
@@ -186,4 +203,309 @@ public partial class SafeChildProcessHandleTests
                 $"Grandchild should have been killed quickly, took {stopwatch.ElapsedMilliseconds}ms");
         }
     }
+
+    [Fact]
+    public void GetExecutablePath_AfterProcessWasWaitedFor_ReturnsNull()
+    {
+        ProcessStartOptions options = new("true");
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+        processHandle.WaitForExit();
+
+        // Once the process is reaped, its PID may get reused, so the path must not be reported.
+        Assert.Null(processHandle.GetExecutablePath());
+    }
+
+    [Fact]
+    public void LastOperationError_IsNullWhenNoOperationFailed()
+    {
+        ProcessStartOptions options = new("sleep") { Arguments = { "10" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+        processHandle.Signal(PosixSignal.SIGCONT);
+        processHandle.Kill();
+        processHandle.WaitForExit();
+
+        Assert.Null(processHandle.LastOperationError);
+    }
+
+    [Fact]
+    public void LastOperationError_IsPopulatedWhenSignalingExitedProcessFails()
+    {
+        ProcessStartOptions options = new("true");
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+        processHandle.WaitForExit();
+
+        Win32Exception thrown = Assert.Throws<Win32Exception>(() => processHandle.Signal(PosixSignal.SIGTERM));
+
+        Assert.Same(thrown, processHandle.LastOperationError);
+        Assert.Equal(3, processHandle.LastOperationError!.NativeErrorCode); // ESRCH
+    }
+
+    [Fact]
+    public void Start_ReadEndOfExistingPipeCanBeUsedAsStandardInput()
+    {
+        File.CreatePipe(out SafeFileHandle readPipe, out SafeFileHandle writePipe);
+
+        using (readPipe)
+        {
+            using (FileStream writer = new(writePipe, FileAccess.Write, bufferSize: 0))
+            {
+                writer.Write("hello from pipe\n"u8);
+            }
+
+            // The read end is handed to the child as-is, the child gets EOF because the write end is closed.
+            ProcessOutput output = ChildProcess.CaptureOutput(new("cat"), input: readPipe);
+
+            Assert.Equal(0, output.ExitStatus.ExitCode);
+            Assert.Equal("hello from pipe\n", output.StandardOutput);
+        }
+    }
+
+    [Fact]
+    public void GetHandleCount_ReturnsMinusOne()
+    {
+        ProcessStartOptions options = new("sleep") { Arguments = { "60" } };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        Assert.Equal(-1, processHandle.GetHandleCount());
+
+        Assert.True(processHandle.Kill());
+    }
+
+    [Fact]
+    public void WaitForRawStatus_ReturnsUnmodifiedStatus_OfSignaledChild()
+    {
+        ProcessStartOptions options = new("sleep") { Arguments = { "60" } };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        processHandle.Signal(PosixSignal.SIGTERM);
+        int rawStatus = processHandle.WaitForRawStatus();
+
+        // WIFSIGNALED and WTERMSIG, SIGTERM is 15 on all Unixes we support.
+        Assert.True(WIFSIGNALED(rawStatus), $"Expected a signaled status, got 0x{rawStatus:X}");
+        Assert.Equal(15, WTERMSIG(rawStatus));
+
+        // The exit has been reaped and cached, the cooked accessors report the same.
+        ProcessExitStatus exitStatus = processHandle.WaitForExit();
+        Assert.Equal(PosixSignal.SIGTERM, exitStatus.Signal);
+        Assert.Equal(128 + WTERMSIG(rawStatus), exitStatus.ExitCode);
+        Assert.Throws<InvalidOperationException>(() => processHandle.WaitForRawStatus());
+    }
+
+    [Fact]
+    public void WaitForRawStatus_ReportsStoppedChild_WithoutReapingIt()
+    {
+        ProcessStartOptions options = new("sleep") { Arguments = { "60" } };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        processHandle.Signal(PosixSignal.SIGSTOP);
+        int rawStatus = processHandle.WaitForRawStatus();
+
+        // WIFSTOPPED and WSTOPSIG, SIGSTOP is 19 on Linux and 17 on macOS and FreeBSD.
+        Assert.Equal(0x7F, rawStatus & 0xFF);
+        Assert.Equal(OperatingSystem.IsLinux() ? 19 : 17, (rawStatus >> 8) & 0xFF);
+        Assert.False(processHandle.TryWaitForExit(TimeSpan.Zero, out _));
+
+        processHandle.Signal(PosixSignal.SIGKILL);
+        Assert.Equal(PosixSignal.SIGKILL, processHandle.WaitForExit().Signal);
+    }
+
+    [Fact]
+    public void TraceChildWithPtrace_StopsAtExec_AndRunsNormallyAfterDetach()
+    {
+        ProcessStartOptions options = new("sh") { Arguments = { "-c", "exit 42" }, TraceChildWithPtrace = true };
+
+        if (!OperatingSystem.IsLinux())
+        {
+            Assert.Throws<PlatformNotSupportedException>(() => SafeChildProcessHandle.Start(options, input: null, output: null, error: null));
+            return;
+        }
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        // Start returns once the child has stopped on the SIGTRAP delivered by exec.
+        Assert.Contains("State:\tt (tracing stop)", File.ReadAllText($"/proc/{processHandle.ProcessId}/status"));
+        Assert.False(processHandle.TryWaitForExit(TimeSpan.FromMilliseconds(100), out _));
+
+        processHandle.DetachTracer();
+
+        ProcessExitStatus exitStatus = processHandle.WaitForExitOrKillOnTimeout(TimeSpan.FromSeconds(5));
+        Assert.Equal(42, exitStatus.ExitCode);
+        Assert.Null(exitStatus.Signal);
+        Assert.Throws<Win32Exception>(() => processHandle.DetachTracer());
+    }
+
+    [Fact]
+    public void DumpCore_ProducesCoreFile()
+    {
+        string directory = Directory.CreateDirectory(Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"))).FullName;
+        // The child raises its own core file size limit, so the test process is not affected.
+        ProcessStartOptions options = new("sh") { Arguments = { "-c", "ulimit -c unlimited 2>/dev/null; exec sleep 60" }, WorkingDirectory = directory };
+
+        try
+        {
+            using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+            if (!OperatingSystem.IsLinux())
+            {
+                Assert.Throws<PlatformNotSupportedException>(() => processHandle.DumpCore());
+                processHandle.Kill();
+                return;
+            }
+
+            // Wait for the exec, so the limit has been applied.
+            for (int i = 0; i < 100 && File.ReadAllText($"/proc/{processHandle.ProcessId}/comm").Trim() != "sleep"; i++)
+            {
+                Thread.Sleep(50);
+            }
+
+            string? corePath = processHandle.DumpCore();
+            ProcessExitStatus exitStatus = processHandle.WaitForExitOrKillOnTimeout(TimeSpan.FromSeconds(30));
+            Assert.Equal(PosixSignal.SIGQUIT, exitStatus.Signal);
+
+            if (corePath is null)
+            {
+                return; // core dumps are disabled (hard limit is zero) or handled by a system service on this machine
+            }
+
+            Assert.StartsWith(directory, corePath);
+            Assert.True(File.Exists(corePath), $"Expected a core file at {corePath}");
+        }
+        finally
+        {
+            Directory.Delete(directory, recursive: true);
+        }
+    }
+
+    [Fact]
+    public void GetLoadedModules_ContainsExecutableAndLibc()
+    {
+        ProcessStartOptions options = new("sleep") { Arguments = { "10" } };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        try
+        {
+            if (!OperatingSystem.IsLinux())
+            {
+                Assert.Throws<PlatformNotSupportedException>(() => processHandle.GetLoadedModules());
+                return;
+            }
+
+            // Wait for the dynamic linker to map libc.
+            IReadOnlyList<string> modules = processHandle.GetLoadedModules();
+            for (int i = 0; i < 100 && !modules.Any(IsLibc); i++)
+            {
+                Thread.Sleep(50);
+                modules = processHandle.GetLoadedModules();
+            }
+
+            Assert.True(modules.Any(IsLibc), $"libc not found in: {string.Join(", ", modules)}");
+            Assert.Contains(processHandle.GetExecutablePath(), modules);
+            Assert.Equal(modules.Count, modules.Distinct().Count());
+            Assert.All(modules, module => Assert.StartsWith("/", module));
+        }
+        finally
+        {
+            processHandle.Kill();
+            processHandle.WaitForExit();
+        }
+
+        Assert.Throws<InvalidOperationException>(() => processHandle.GetLoadedModules());
+
+        // glibc (libc.so.6) or musl (ld-musl-x86_64.so.1, which is the libc as well)
+        static bool IsLibc(string module) => Path.GetFileName(module) is string name && (name.StartsWith("libc.so") || name.StartsWith("libc-") || name.StartsWith("ld-musl"));
+    }
+
+    [Fact]
+    public void AddLibrarySearchPath_ChildLoadsColocatedLibrary()
+    {
+        if (!OperatingSystem.IsLinux())
+        {
+            return; // GetLoadedModules is Linux only
+        }
+
+        string? libcPath = GetLoadedModulesOfSleep(new ProcessStartOptions("sleep") { Arguments = { "10" } })
+            .FirstOrDefault(module => Path.GetFileName(module).StartsWith("libc.so", StringComparison.Ordinal));
+        if (libcPath is null)
+        {
+            return; // e.g. musl, where libc is the dynamic loader itself and is never searched for
+        }
+
+        string directory = Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"));
+        Directory.CreateDirectory(directory);
+        try
+        {
+            string colocatedLibc = Path.Combine(directory, Path.GetFileName(libcPath));
+            File.Copy(libcPath, colocatedLibc);
+
+            ProcessStartOptions options = new("sleep") { Arguments = { "10" } };
+            options.AddLibrarySearchPath(directory);
+
+            Assert.Contains(colocatedLibc, GetLoadedModulesOfSleep(options));
+        }
+        finally
+        {
+            Directory.Delete(directory, recursive: true);
+        }
+
+        static IReadOnlyList<string> GetLoadedModulesOfSleep(ProcessStartOptions options)
+        {
+            using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+            try
+            {
+                // Wait for the dynamic linker to map the libraries.
+                IReadOnlyList<string> modules = processHandle.GetLoadedModules();
+                for (int i = 0; i < 100 && !modules.Any(module => Path.GetFileName(module).StartsWith("libc", StringComparison.Ordinal)); i++)
+                {
+                    Thread.Sleep(50);
+                    modules = processHandle.GetLoadedModules();
+                }
+                return modules;
+            }
+            finally
+            {
+                processHandle.Kill();
+                processHandle.WaitForExit();
+            }
+        }
+    }
+
+    [Fact]
+    public void CloseMainWindow_ThrowsPlatformNotSupported()
+    {
+        ProcessStartOptions options = new("sleep") { Arguments = { "10" } };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        Assert.Throws<PlatformNotSupportedException>(() => processHandle.CloseMainWindow());
+
+        processHandle.Kill();
+        processHandle.WaitForExit();
+    }
+
+    [Fact]
+    public void ShutdownToken_KillsChildIgnoringSIGTERM_AfterGracePeriod()
+    {
+        using CancellationTokenSource shutdown = new();
+        // The ignored disposition survives exec.
+        ProcessStartOptions options = new("sh")
+        {
+            Arguments = { "-c", "trap '' TERM; exec sleep 60" },
+            ShutdownToken = shutdown.Token,
+            ShutdownGracePeriod = TimeSpan.FromMilliseconds(500),
+        };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+        Thread.Sleep(200); // let the shell install the trap
+
+        shutdown.Cancel();
+
+        Assert.False(processHandle.TryWaitForExit(TimeSpan.FromMilliseconds(300), out _));
+        Assert.True(processHandle.TryWaitForExit(TimeSpan.FromSeconds(10), out ProcessExitStatus? exitStatus));
+        Assert.Equal(PosixSignal.SIGKILL, exitStatus.Signal);
+    }
+
+    private static bool WIFSIGNALED(int status) => (status & 0x7F) != 0 && (status & 0x7F) != 0x7F;
+
+    private static int WTERMSIG(int status) => status & 0x7F;
 }
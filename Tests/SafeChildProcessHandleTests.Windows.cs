@@ -1,4 +1,6 @@
 using System;
+using System.Collections.Generic;
+using System.Threading;
 using System.TBA;
 using PosixSignal = System.TBA.PosixSignal;
 using Microsoft.Win32.SafeHandles;
@@ -150,4 +152,117 @@ public partial class SafeChildProcessHandleTests
 
         Assert.True(processHandle.Kill());
     }
+
+    [Fact]
+    public void GetHandleCount_IncreasesWhenChildOpensHandles()
+    {
+        // The child waits a moment before opening the handles, so we can take the baseline first.
+        ProcessStartOptions options = new("powershell")
+        {
+            Arguments =
+            {
+                "-InputFormat", "None", "-Command",
+                "Write-Output 'started'; Start-Sleep 2; $files = 1..500 | ForEach-Object { [System.IO.File]::OpenRead($env:ComSpec) }; Write-Output 'opened'; Start-Sleep 30"
+            },
+        };
+
+        ProcessOutputLines lines = ChildProcess.StreamOutputLines(options, TimeSpan.FromSeconds(60));
+        using IEnumerator<ProcessOutputLine> enumerator = lines.GetEnumerator();
+
+        Assert.True(enumerator.MoveNext());
+        Assert.Equal("started", enumerator.Current.Content);
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Open(lines.ProcessId);
+        int before = processHandle.GetHandleCount();
+
+        Assert.True(enumerator.MoveNext());
+        Assert.Equal("opened", enumerator.Current.Content);
+        int after = processHandle.GetHandleCount();
+
+        Assert.InRange(after, before + 500, int.MaxValue);
+
+        Assert.True(processHandle.Kill());
+        processHandle.WaitForExit();
+        Assert.Equal(-1, processHandle.GetHandleCount());
+    }
+
+    [Fact]
+    public void WaitForRawStatus_ThrowsPlatformNotSupported()
+    {
+        ProcessStartOptions options = new("cmd.exe") { Arguments = { "/c", "exit 0" } };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        Assert.Throws<PlatformNotSupportedException>(() => processHandle.WaitForRawStatus());
+
+        processHandle.WaitForExit();
+    }
+
+    [Fact]
+    public void TraceChildWithPtrace_ThrowsPlatformNotSupported()
+    {
+        ProcessStartOptions options = new("cmd.exe") { Arguments = { "/c", "exit 0" }, TraceChildWithPtrace = true };
+
+        Assert.Throws<PlatformNotSupportedException>(() => SafeChildProcessHandle.Start(options, input: null, output: null, error: null));
+    }
+
+    [Fact]
+    public void GetLoadedModules_ThrowsPlatformNotSupported()
+    {
+        ProcessStartOptions options = new("cmd.exe") { Arguments = { "/c", "exit 0" } };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        Assert.Throws<PlatformNotSupportedException>(() => processHandle.GetLoadedModules());
+
+        processHandle.WaitForExit();
+    }
+
+    [Fact]
+    public void CloseMainWindow_AsksGuiAppToClose()
+    {
+        // The app exits with 42 only when the form has been closed, a killed process would report a different exit code.
+        ProcessStartOptions options = new("powershell")
+        {
+            Arguments =
+            {
+                "-InputFormat", "None", "-Command",
+                "Add-Type -AssemblyName System.Windows.Forms; [System.Windows.Forms.Application]::Run((New-Object System.Windows.Forms.Form)); exit 42"
+            },
+            CreateNoWindow = true, // no console window, the form is the only top-level window
+        };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        try
+        {
+            // Loading WinForms takes a while, the form is not shown right away.
+            bool closed = processHandle.CloseMainWindow();
+            for (int i = 0; i < 300 && !closed; i++)
+            {
+                Thread.Sleep(100);
+                closed = processHandle.CloseMainWindow();
+            }
+
+            Assert.True(closed);
+            Assert.True(processHandle.TryWaitForExit(TimeSpan.FromSeconds(5), out ProcessExitStatus? exitStatus));
+            Assert.Equal(42, exitStatus.ExitCode);
+        }
+        finally
+        {
+            processHandle.Kill();
+        }
+    }
+
+    [Fact]
+    public void CloseMainWindow_ReturnsFalse_ForProcessWithoutWindows()
+    {
+        ProcessStartOptions options = new("powershell")
+        {
+            Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 10" },
+            CreateNoWindow = true,
+        };
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        Assert.False(processHandle.CloseMainWindow());
+
+        Assert.True(processHandle.Kill());
+        processHandle.WaitForExit();
+    }
 }
@@ -2,6 +2,7 @@ using System;
 using System.Collections.Generic;
 using System.Diagnostics;
 using System.IO;
+using System.Linq;
 using System.TBA;
 using PosixSignal = System.TBA.PosixSignal;
 using System.Threading;
@@ -38,6 +39,254 @@ public partial class SafeChildProcessHandleTests
         Assert.Equal(0, exitStatus.ExitCode);
         Assert.Null(exitStatus.Signal);
         Assert.False(exitStatus.Canceled);
+        Assert.Equal(ProcessExitReason.Exited, exitStatus.Reason);
+    }
+
+    [Fact]
+    public static void GetExecutablePath_ReturnsPathOfRunningExecutable()
+    {
+        ProcessStartOptions options = ProcessStartOptions.ResolvePath(OperatingSystem.IsWindows() ? "powershell" : "sleep");
+        options.Arguments = OperatingSystem.IsWindows()
+            ? ["-InputFormat", "None", "-Command", "Start-Sleep 10"]
+            : ["10"];
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        try
+        {
+            string? executablePath = processHandle.GetExecutablePath();
+
+            Assert.NotNull(executablePath);
+            // The OS reports the path with all symbolic links resolved (e.g. /bin -> /usr/bin).
+            Assert.Equal(GetRealPath(options.FileName), GetRealPath(executablePath), ignoreCase: OperatingSystem.IsWindows());
+        }
+        finally
+        {
+            processHandle.Kill();
+            processHandle.WaitForExit();
+        }
+    }
+
+    [Fact]
+    public static void Start_ThrowsForWriteOnlyStandardInput()
+    {
+        File.CreatePipe(out SafeFileHandle readPipe, out SafeFileHandle writePipe);
+
+        using (readPipe)
+        using (writePipe)
+        {
+            ProcessStartOptions options = OperatingSystem.IsWindows()
+                ? new("cmd") { Arguments = { "/c", "exit 0" } }
+                : new("sh") { Arguments = { "-c", "exit 0" } };
+
+            Assert.Throws<ArgumentException>(() => SafeChildProcessHandle.Start(options, input: writePipe, output: null, error: null));
+            Assert.False(writePipe.IsClosed);
+        }
+    }
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task GetCpuUsagePercentage_BusyLoopChild_ReportsNonTrivialUsage(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "while ($true) {}" } }
+            : new("sh") { Arguments = { "-c", "while :; do :; done" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        try
+        {
+            double percentage = useAsync
+                ? await processHandle.GetCpuUsagePercentageAsync(TimeSpan.FromMilliseconds(500))
+                : processHandle.GetCpuUsagePercentage(TimeSpan.FromMilliseconds(500));
+
+            // A busy loop keeps (at least) one core busy, we use a low threshold to tolerate overloaded CI machines.
+            Assert.InRange(percentage, 20, 100.0 * Environment.ProcessorCount + 10);
+        }
+        finally
+        {
+            processHandle.Kill();
+            processHandle.WaitForExit();
+        }
+    }
+
+    [Theory]
+    [InlineData(true)]
+    [InlineData(false)]
+    public static async Task GetCpuUsagePercentage_IdleChild_ReportsNearZeroUsage(bool useAsync)
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 10" } }
+            : new("sleep") { Arguments = { "10" } };
+
+        // A suspended process does not consume any CPU time, including its startup.
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.StartSuspended(options, input: null, output: null, error: null);
+
+        try
+        {
+            double percentage = useAsync
+                ? await processHandle.GetCpuUsagePercentageAsync(TimeSpan.FromMilliseconds(500))
+                : processHandle.GetCpuUsagePercentage(TimeSpan.FromMilliseconds(500));
+
+            Assert.InRange(percentage, 0, 5);
+        }
+        finally
+        {
+            processHandle.Kill();
+            processHandle.WaitForExit();
+        }
+    }
+
+    [Fact]
+    public static void GetCpuUsagePercentage_ThrowsForNonPositiveSamplingInterval()
+    {
+        using SafeChildProcessHandle processHandle = new();
+
+        Assert.Throws<ArgumentOutOfRangeException>(() => processHandle.GetCpuUsagePercentage(TimeSpan.Zero));
+        // Thrown synchronously, before the task is returned.
+        Assert.Throws<ArgumentOutOfRangeException>(() => processHandle.GetCpuUsagePercentageAsync(TimeSpan.Zero));
+    }
+
+    [Fact]
+    public static async Task GetCpuUsagePercentageAsync_ThrowsOnCancellation()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 10" } }
+            : new("sleep") { Arguments = { "10" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        try
+        {
+            using CancellationTokenSource cts = new(TimeSpan.FromMilliseconds(100));
+
+            await Assert.ThrowsAnyAsync<OperationCanceledException>(() => processHandle.GetCpuUsagePercentageAsync(TimeSpan.FromSeconds(30), cts.Token));
+        }
+        finally
+        {
+            processHandle.Kill();
+            processHandle.WaitForExit();
+        }
+    }
+
+    [Fact]
+    public static void WaitForExitWithHeartbeat_InvokesHeartbeatUntilChildExits()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 1" } }
+            : new("sleep") { Arguments = { "1" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        int heartbeats = 0;
+        ProcessExitStatus exitStatus = processHandle.WaitForExitWithHeartbeat(TimeSpan.FromMilliseconds(100), () => heartbeats++);
+
+        Assert.Equal(0, exitStatus.ExitCode);
+        // The child runs for at least a second, the upper bound tolerates slow (PowerShell) startup.
+        Assert.InRange(heartbeats, 5, 100);
+
+        // It has exited, so there is nothing to wait for anymore.
+        processHandle.WaitForExitWithHeartbeat(TimeSpan.FromMilliseconds(100), () => heartbeats = -1);
+        Assert.NotEqual(-1, heartbeats);
+    }
+
+    [Fact]
+    public static void WaitForExitWithHeartbeat_ThrowsForInvalidArguments()
+    {
+        using SafeChildProcessHandle processHandle = new();
+
+        Assert.Throws<ArgumentOutOfRangeException>(() => processHandle.WaitForExitWithHeartbeat(TimeSpan.Zero, () => { }));
+        Assert.Throws<ArgumentOutOfRangeException>(() => processHandle.WaitForExitWithHeartbeat(Timeout.InfiniteTimeSpan, () => { }));
+        Assert.Throws<ArgumentNullException>(() => processHandle.WaitForExitWithHeartbeat(TimeSpan.FromSeconds(1), null!));
+    }
+
+    [Fact]
+    public static void Elapsed_IsMeasuredFromStartToObservedExit()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 1" } }
+            : new("sleep") { Arguments = { "1" } };
+
+        DateTimeOffset beforeStart = DateTimeOffset.UtcNow;
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        Assert.InRange(processHandle.StartTime!.Value, beforeStart, DateTimeOffset.UtcNow);
+        Assert.Null(processHandle.ExitTime);
+        Assert.InRange(processHandle.Elapsed!.Value, TimeSpan.Zero, TimeSpan.FromSeconds(1));
+
+        processHandle.WaitForExit();
+
+        TimeSpan elapsed = processHandle.Elapsed!.Value;
+        Assert.InRange(elapsed, TimeSpan.FromSeconds(1), TimeSpan.FromSeconds(10));
+        Assert.NotNull(processHandle.ExitTime);
+
+        // The process has exited, so it does not grow anymore.
+        Thread.Sleep(100);
+        Assert.Equal(elapsed, processHandle.Elapsed);
+    }
+
+    [Fact]
+    public static void Elapsed_IsNotAffectedByWallClockChange()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 1" } }
+            : new("sleep") { Arguments = { "1" } };
+        WallClockJumpingBackTimeProvider timeProvider = new(TimeSpan.FromHours(1));
+        options.StartTimeMonotonicSource = timeProvider;
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+        timeProvider.Jump();
+        processHandle.WaitForExit();
+
+        // The wall clock went back, the monotonic one did not.
+        Assert.True(processHandle.ExitTime < processHandle.StartTime);
+        Assert.InRange(processHandle.Elapsed!.Value, TimeSpan.FromSeconds(1), TimeSpan.FromSeconds(10));
+    }
+
+    private sealed class WallClockJumpingBackTimeProvider(TimeSpan jump) : TimeProvider
+    {
+        private TimeSpan _offset;
+
+        internal void Jump() => _offset = -jump;
+
+        // Timestamps come from the base implementation (Stopwatch), so they don't jump.
+        public override DateTimeOffset GetUtcNow() => base.GetUtcNow() + _offset;
+    }
+
+    [Fact]
+    public static void SetPriority_ChangesPriorityOfRunningChild()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 10" } }
+            : new("sleep") { Arguments = { "10" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        try
+        {
+            Assert.Equal(ProcessPriority.Normal, processHandle.GetPriority());
+
+            // Lowering the priority does not require elevated privileges on any platform.
+            processHandle.SetPriority(ProcessPriority.BelowNormal);
+            Assert.Equal(ProcessPriority.BelowNormal, processHandle.GetPriority());
+
+            processHandle.SetPriority(ProcessPriority.Idle);
+            Assert.Equal(ProcessPriority.Idle, processHandle.GetPriority());
+        }
+        finally
+        {
+            processHandle.Kill();
+            processHandle.WaitForExit();
+        }
+    }
+
+    [Fact]
+    public static void SetPriority_ThrowsForUndefinedValue()
+    {
+        using SafeChildProcessHandle processHandle = new();
+
+        Assert.Throws<ArgumentOutOfRangeException>(() => processHandle.SetPriority((ProcessPriority)42));
     }
 
     [Fact]
@@ -133,6 +382,407 @@ public partial class SafeChildProcessHandleTests
         }
     }
 
+    [Fact]
+    public static void WorkingDirectoryRelativeToExecutable_ResolvesDotSlashPathAgainstExecutableDirectory()
+    {
+        string executableDirectory = Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"));
+        string dataDirectory = Directory.CreateDirectory(Path.Combine(executableDirectory, "data")).FullName;
+
+        try
+        {
+            ProcessStartOptions options = CreatePrintWorkingDirectoryExecutable(executableDirectory);
+            options.WorkingDirectory = OperatingSystem.IsWindows() ? ".\\data" : "./data";
+            options.WorkingDirectoryRelativeToExecutable = true;
+
+            ProcessOutput output = ChildProcess.CaptureOutput(options);
+
+            Assert.Equal(0, output.ExitStatus.ExitCode);
+            Assert.Equal(GetRealPath(dataDirectory), GetRealPath(output.StandardOutput.Trim()), ignoreCase: OperatingSystem.IsWindows());
+        }
+        finally
+        {
+            Directory.Delete(executableDirectory, recursive: true);
+        }
+    }
+
+    [Fact]
+    public static void WorkingDirectoryRelativeToExecutable_DoesNotAffectPathsWithoutDotSlashPrefix()
+    {
+        string executableDirectory = Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"));
+        Directory.CreateDirectory(executableDirectory);
+
+        try
+        {
+            ProcessStartOptions options = CreatePrintWorkingDirectoryExecutable(executableDirectory);
+            // "." is relative to the current directory of the parent process.
+            options.WorkingDirectory = ".";
+            options.WorkingDirectoryRelativeToExecutable = true;
+
+            ProcessOutput output = ChildProcess.CaptureOutput(options);
+
+            Assert.Equal(0, output.ExitStatus.ExitCode);
+            Assert.Equal(GetRealPath(Environment.CurrentDirectory), GetRealPath(output.StandardOutput.Trim()), ignoreCase: OperatingSystem.IsWindows());
+        }
+        finally
+        {
+            Directory.Delete(executableDirectory, recursive: true);
+        }
+    }
+
+    [Fact]
+    public static void PropagateOpenTelemetryContext_PassesCurrentActivityToChild()
+    {
+        using Activity activity = new Activity(nameof(PropagateOpenTelemetryContext_PassesCurrentActivityToChild)).SetIdFormat(ActivityIdFormat.W3C);
+        activity.TraceStateString = "vendor=value";
+        activity.Start();
+
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd.exe") { Arguments = { "/c", "echo %TRACEPARENT%;%TRACESTATE%" } }
+            : new("sh") { Arguments = { "-c", "echo \"$TRACEPARENT;$TRACESTATE\"" } };
+        options.PropagateOpenTelemetryContext = true;
+
+        ProcessOutput output = ChildProcess.CaptureOutput(options);
+
+        Assert.Equal(0, output.ExitStatus.ExitCode);
+        Assert.Equal($"{activity.Id};vendor=value", output.StandardOutput.Trim());
+        Assert.Equal(
+            new EnvironmentVariableDifference[]
+            {
+                new("TRACEPARENT", parentValue: null, activity.Id),
+                new("TRACESTATE", parentValue: null, "vendor=value"),
+            },
+            options.GetEnvironmentDiffAgainstParent());
+
+        // The variables are added to a copy, the user's environment is untouched.
+        activity.Stop();
+        Assert.Empty(options.GetEnvironmentDiffAgainstParent());
+    }
+
+    [Fact]
+    public static void PropagateOpenTelemetryContext_AddsNothing_WithoutCurrentActivity()
+    {
+        Assert.Null(Activity.Current);
+
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd.exe") { Arguments = { "/c", "if defined TRACEPARENT (exit 1) else (exit 0)" } }
+            : new("sh") { Arguments = { "-c", "test -z \"${TRACEPARENT+set}\"" } };
+        options.PropagateOpenTelemetryContext = true;
+
+        Assert.Equal(0, ChildProcess.Inherit(options).ExitCode);
+    }
+
+    [Fact]
+    public static void ShutdownToken_TerminatesAllOutstandingChildrenWithinGracePeriod()
+    {
+        using CancellationTokenSource shutdown = new();
+        ProcessStartOptions CreateOptions(bool createNewProcessGroup)
+        {
+            ProcessStartOptions options = OperatingSystem.IsWindows()
+                ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 60" } }
+                : new("sleep") { Arguments = { "60" } };
+            options.CreateNewProcessGroup = createNewProcessGroup;
+            options.ShutdownToken = shutdown.Token;
+            options.ShutdownGracePeriod = TimeSpan.FromSeconds(2);
+            return options;
+        }
+
+        using SafeChildProcessHandle first = SafeChildProcessHandle.Start(CreateOptions(createNewProcessGroup: false), input: null, output: null, error: null);
+        using SafeChildProcessHandle second = SafeChildProcessHandle.Start(CreateOptions(createNewProcessGroup: true), input: null, output: null, error: null);
+        using SafeChildProcessHandle exited = SafeChildProcessHandle.Start(OperatingSystem.IsWindows()
+            ? new("cmd.exe") { Arguments = { "/c", "exit 0" }, ShutdownToken = shutdown.Token }
+            : new("sh") { Arguments = { "-c", "exit 0" }, ShutdownToken = shutdown.Token }, input: null, output: null, error: null);
+        Assert.Equal(0, exited.WaitForExit().ExitCode);
+
+        Assert.False(first.TryWaitForExit(TimeSpan.FromMilliseconds(100), out _));
+
+        Stopwatch stopwatch = Stopwatch.StartNew();
+        shutdown.Cancel(); // does not wait for the children
+        Assert.InRange(stopwatch.Elapsed, TimeSpan.Zero, TimeSpan.FromSeconds(1));
+
+        // The grace period plus some slack for the wait.
+        Assert.True(first.TryWaitForExit(TimeSpan.FromSeconds(10), out ProcessExitStatus? firstStatus));
+        Assert.True(second.TryWaitForExit(TimeSpan.FromSeconds(10), out ProcessExitStatus? secondStatus));
+        Assert.NotEqual(0, firstStatus.ExitCode);
+        Assert.NotEqual(0, secondStatus.ExitCode);
+        if (!OperatingSystem.IsWindows())
+        {
+            // Both exited on SIGTERM, nothing had to be killed.
+            Assert.Equal(PosixSignal.SIGTERM, firstStatus.Signal);
+            Assert.Equal(PosixSignal.SIGTERM, secondStatus.Signal);
+        }
+        Assert.Null(first.LastOperationError);
+        Assert.Null(exited.LastOperationError);
+    }
+
+    [Fact]
+    public static void ShutdownGracePeriod_CannotBeNegative()
+    {
+        ProcessStartOptions options = new("test_executable");
+
+        Assert.Equal(TimeSpan.FromSeconds(5), options.ShutdownGracePeriod);
+        Assert.Throws<ArgumentOutOfRangeException>(() => options.ShutdownGracePeriod = TimeSpan.FromMilliseconds(-1));
+    }
+
+    [Fact]
+    public static void AddEnvironmentLayer_LaterLayersOverrideEarlierOnesAndNullUnsets()
+    {
+        string suffix = Guid.NewGuid().ToString("N");
+        string inheritedName = "LAYER_INHERITED_" + suffix;
+        string baseOnlyName = "LAYER_BASE_" + suffix;
+        string overriddenName = "LAYER_OVERRIDDEN_" + suffix;
+        string secretName = "LAYER_SECRET_" + suffix;
+        SetEnvVarForReal(inheritedName, "inherited_value");
+
+        try
+        {
+            ProcessStartOptions options = CreatePrintEnvVarToOutputOptions(overriddenName);
+            options.AddEnvironmentLayer(new Dictionary<string, string?>
+            {
+                [baseOnlyName] = "base",
+                [overriddenName] = "base",
+                [secretName] = "base",
+            });
+            options.AddEnvironmentLayer(new Dictionary<string, string?>
+            {
+                [overriddenName] = "override",
+                [inheritedName] = null,
+            });
+            options.AddEnvironmentLayer(new Dictionary<string, string?>
+            {
+                [secretName] = "secret",
+            });
+
+            Assert.Equal(
+                new EnvironmentVariableDifference[]
+                {
+                    new(baseOnlyName, parentValue: null, "base"),
+                    new(inheritedName, "inherited_value", childValue: null),
+                    new(overriddenName, parentValue: null, "override"),
+                    new(secretName, parentValue: null, "secret"),
+                },
+                options.GetEnvironmentDiffAgainstParent());
+            Assert.Equal("override", GetSingleOutputLine(options).Trim());
+        }
+        finally
+        {
+            SetEnvVarForReal(inheritedName, null);
+        }
+    }
+
+    [Fact]
+    public static void AddEnvironmentLayer_FollowsCaseRulesOfOptions()
+    {
+        string name = "LAYER_CASE_" + Guid.NewGuid().ToString("N");
+        ProcessStartOptions options = new("test_executable") { EnvironmentCaseSensitivityOverride = EnvironmentCaseSensitivity.CaseInsensitive };
+
+        // The ordinal comparer of the layer does not matter.
+        options.AddEnvironmentLayer(new Dictionary<string, string?> { [name] = "first" });
+        options.AddEnvironmentLayer(new Dictionary<string, string?> { [name.ToLowerInvariant()] = "second" });
+
+        Assert.Equal("second", Assert.Single(options.Environment, pair => pair.Key.Equals(name, StringComparison.OrdinalIgnoreCase)).Value);
+    }
+
+    [Fact]
+    public static void AddLibrarySearchPath_PrependsToPlatformSpecificVariable()
+    {
+        string variableName = OperatingSystem.IsWindows() ? "PATH" : OperatingSystem.IsMacOS() ? "DYLD_LIBRARY_PATH" : "LD_LIBRARY_PATH";
+        string first = Path.Combine(Path.GetTempPath(), "first");
+        string second = Path.Combine(Path.GetTempPath(), "second");
+        ProcessStartOptions options = CreatePrintEnvVarToOutputOptions(variableName);
+        options.Environment[variableName] = "inherited";
+
+        options.AddLibrarySearchPath(first);
+        options.AddLibrarySearchPath(second);
+
+        string expected = string.Join(Path.PathSeparator, second, first, "inherited");
+        Assert.Equal(expected, options.Environment[variableName]);
+
+        // printenv is protected by SIP on macOS, which removes DYLD_* variables.
+        if (!OperatingSystem.IsMacOS())
+        {
+            Assert.Equal(expected, GetSingleOutputLine(options).Trim());
+        }
+    }
+
+    [Fact]
+    public static void AddLibrarySearchPath_DoesNotAddSeparator_WhenVariableIsNotSet()
+    {
+        string variableName = OperatingSystem.IsWindows() ? "PATH" : OperatingSystem.IsMacOS() ? "DYLD_LIBRARY_PATH" : "LD_LIBRARY_PATH";
+        string directory = Path.GetTempPath();
+        ProcessStartOptions options = new("test_executable");
+        options.Environment.Remove(variableName);
+
+        options.AddLibrarySearchPath(directory);
+
+        Assert.Equal(directory, options.Environment[variableName]);
+        Assert.Throws<ArgumentException>(() => options.AddLibrarySearchPath(""));
+    }
+
+    [Fact]
+    public static void GetEnvironmentDiffAgainstParent_IsEmptyWhenEnvironmentWasNotModified()
+    {
+        ProcessStartOptions options = new("test_executable");
+
+        Assert.Empty(options.GetEnvironmentDiffAgainstParent());
+
+        _ = options.Environment;
+
+        Assert.Empty(options.GetEnvironmentDiffAgainstParent());
+    }
+
+    [Fact]
+    public static void GetEnvironmentDiffAgainstParent_ReportsAddedRemovedAndChangedVariables()
+    {
+        string suffix = Guid.NewGuid().ToString("N");
+        string addedName = "DIFF_ADDED_" + suffix;
+        string removedName = "DIFF_REMOVED_" + suffix;
+        string deletedName = "DIFF_DELETED_" + suffix;
+        string changedName = "DIFF_CHANGED_" + suffix;
+        string unchangedName = "DIFF_UNCHANGED_" + suffix;
+        SetEnvVarForReal(removedName, "removed_value");
+        SetEnvVarForReal(deletedName, "deleted_value");
+        SetEnvVarForReal(changedName, "old_value");
+        SetEnvVarForReal(unchangedName, "same_value");
+
+        try
+        {
+            ProcessStartOptions options = new("test_executable");
+            options.Environment[addedName] = "added_value";
+            options.Environment[removedName] = null;
+            options.Environment.Remove(deletedName);
+            options.Environment[changedName] = "new_value";
+            options.Environment[unchangedName] = "same_value";
+
+            IReadOnlyList<EnvironmentVariableDifference> diff = options.GetEnvironmentDiffAgainstParent();
+
+            Assert.Equal(
+                new EnvironmentVariableDifference[]
+                {
+                    new(addedName, parentValue: null, "added_value"),
+                    new(changedName, "old_value", "new_value"),
+                    new(deletedName, "deleted_value", childValue: null),
+                    new(removedName, "removed_value", childValue: null),
+                },
+                diff);
+            Assert.True(diff[0].IsAdded);
+            Assert.True(diff[1].IsChanged);
+            Assert.True(diff[2].IsRemoved);
+            Assert.True(diff[3].IsRemoved);
+        }
+        finally
+        {
+            SetEnvVarForReal(removedName, null);
+            SetEnvVarForReal(deletedName, null);
+            SetEnvVarForReal(changedName, null);
+            SetEnvVarForReal(unchangedName, null);
+        }
+    }
+
+    [Fact]
+    public static void GetEnvironmentDiffAgainstParent_RespectsPlatformCaseRules()
+    {
+        string name = "DIFF_CASE_" + Guid.NewGuid().ToString("N");
+        SetEnvVarForReal(name, "value");
+
+        try
+        {
+            ProcessStartOptions options = new("test_executable");
+            options.Environment.Remove(name);
+            options.Environment[name.ToLowerInvariant()] = "value";
+
+            IReadOnlyList<EnvironmentVariableDifference> diff = options.GetEnvironmentDiffAgainstParent();
+
+            if (OperatingSystem.IsWindows())
+            {
+                // Names are case-insensitive, so it's the same variable with the same value.
+                Assert.Empty(diff);
+            }
+            else
+            {
+                Assert.Equal(
+                    new EnvironmentVariableDifference[]
+                    {
+                        new(name, "value", childValue: null),
+                        new(name.ToLowerInvariant(), parentValue: null, "value"),
+                    },
+                    diff);
+            }
+        }
+        finally
+        {
+            SetEnvVarForReal(name, null);
+        }
+    }
+
+    [Fact]
+    public static void EffectiveArguments_ReflectsChangesMadeToArguments()
+    {
+        ProcessStartOptions options = new("test_executable");
+
+        Assert.Empty(options.EffectiveArguments);
+
+        options.Arguments.Add("first");
+        options.Arguments.Add("second");
+        Assert.Equal(new[] { "first", "second" }, options.EffectiveArguments);
+
+        options.Arguments.RemoveAt(0);
+        Assert.Equal(new[] { "second" }, options.EffectiveArguments);
+
+        options.Arguments = new List<string> { "replaced" };
+        Assert.Equal(new[] { "replaced" }, options.EffectiveArguments);
+    }
+
+    [Fact]
+    public static void EffectiveArguments_IsNotAffectedByChangesMadeAfterTheRead()
+    {
+        ProcessStartOptions options = new("test_executable") { Arguments = { "first", "second" } };
+
+        IReadOnlyList<string> snapshot = options.EffectiveArguments;
+
+        options.Arguments.Add("third");
+        options.Arguments[0] = "modified";
+        options.Arguments.RemoveAt(1);
+
+        Assert.Equal(new[] { "first", "second" }, snapshot);
+        Assert.Equal(new[] { "modified", "third" }, options.EffectiveArguments);
+    }
+
+    [Fact]
+    public static void EffectiveArguments_CanNotBeUsedToModifyArguments()
+    {
+        ProcessStartOptions options = new("test_executable") { Arguments = { "first" } };
+
+        IList<string> view = Assert.IsAssignableFrom<IList<string>>(options.EffectiveArguments);
+
+        Assert.Throws<NotSupportedException>(() => view.Add("second"));
+        Assert.Throws<NotSupportedException>(() => view[0] = "modified");
+        Assert.Equal(new[] { "first" }, options.Arguments);
+    }
+
+    [Fact]
+    public static void Arguments_ModifiedAfterStart_DoNotAffectStartedProcess()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep -Milliseconds 100; Write-Output original" } }
+            : new("sh") { Arguments = { "-c", "sleep 0.1 && echo original" } };
+
+        File.CreatePipe(out SafeFileHandle readHandle, out SafeFileHandle writeHandle);
+
+        using (readHandle)
+        {
+            using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: writeHandle, error: null);
+
+            options.Arguments[^1] = options.Arguments[^1].Replace("original", "modified");
+            options.Arguments.Add("extra");
+
+            using StreamReader reader = new(new FileStream(readHandle, FileAccess.Read));
+            string content = reader.ReadToEnd();
+
+            Assert.Equal(0, processHandle.WaitForExit().ExitCode);
+            Assert.Equal(OperatingSystem.IsWindows() ? "original\r\n" : "original\n", content);
+        }
+    }
+
     [Theory]
     [InlineData(true)]
     [InlineData(false)]
@@ -225,6 +875,89 @@ public partial class SafeChildProcessHandleTests
         Assert.Equal(testVarValue, outputLine.Trim());
     }
 
+    [Theory]
+    [InlineData(EnvironmentCaseSensitivity.PlatformDefault)]
+    [InlineData(EnvironmentCaseSensitivity.CaseSensitive)]
+    [InlineData(EnvironmentCaseSensitivity.CaseInsensitive)]
+    public static void EnvironmentCaseSensitivityOverride_DeterminesWhetherNamesDifferingByCaseAreMerged(EnvironmentCaseSensitivity caseSensitivity)
+    {
+        string name = "CASE_VAR_" + Guid.NewGuid().ToString("N");
+        string lowerCaseName = name.ToLowerInvariant();
+        ProcessStartOptions options = new("test_executable") { EnvironmentCaseSensitivityOverride = caseSensitivity };
+
+        options.Environment[name] = "first";
+        options.Environment[lowerCaseName] = "second";
+
+        bool expectMerged = caseSensitivity == EnvironmentCaseSensitivity.CaseInsensitive
+            || (caseSensitivity == EnvironmentCaseSensitivity.PlatformDefault && OperatingSystem.IsWindows());
+
+        if (expectMerged)
+        {
+            Assert.Equal("second", options.Environment[name]);
+            Assert.Equal("second", options.Environment[lowerCaseName]);
+            Assert.Single(options.Environment.Keys, key => key.Equals(name, StringComparison.OrdinalIgnoreCase));
+        }
+        else
+        {
+            Assert.Equal("first", options.Environment[name]);
+            Assert.Equal("second", options.Environment[lowerCaseName]);
+            Assert.Equal(2, options.Environment.Keys.Count(key => key.Equals(name, StringComparison.OrdinalIgnoreCase)));
+        }
+    }
+
+    [Fact]
+    public static void EnvironmentCaseSensitivityOverride_RebuildsAlreadyAccessedEnvironment()
+    {
+        string name = "CASE_VAR_" + Guid.NewGuid().ToString("N");
+        ProcessStartOptions options = new("test_executable") { EnvironmentCaseSensitivityOverride = EnvironmentCaseSensitivity.CaseSensitive };
+        options.Environment[name] = "first";
+        options.Environment[name.ToLowerInvariant()] = "second";
+
+        options.EnvironmentCaseSensitivityOverride = EnvironmentCaseSensitivity.CaseInsensitive;
+
+        // The last one wins.
+        Assert.Single(options.Environment.Keys, key => key.Equals(name, StringComparison.OrdinalIgnoreCase));
+        Assert.Equal("second", options.Environment[name.ToLowerInvariant()]);
+    }
+
+    [Fact]
+    public static void EnvironmentCaseSensitivityOverride_ThrowsForUndefinedValue()
+    {
+        ProcessStartOptions options = new("test_executable");
+
+        Assert.Throws<ArgumentOutOfRangeException>(() => options.EnvironmentCaseSensitivityOverride = (EnvironmentCaseSensitivity)3);
+    }
+
+    [Fact]
+    public static void ChildProcess_ReceivesMergedEnvVar_WhenCaseInsensitive()
+    {
+        string testVarName = "MERGED_VAR_" + Guid.NewGuid().ToString("N");
+        ProcessStartOptions options = CreatePrintEnvVarToOutputOptions(testVarName);
+        options.EnvironmentCaseSensitivityOverride = EnvironmentCaseSensitivity.CaseInsensitive;
+
+        options.Environment[testVarName] = "first";
+        options.Environment[testVarName.ToLowerInvariant()] = "second";
+
+        Assert.Equal("second", GetSingleOutputLine(options));
+    }
+
+    [Fact(Skip = ConditionalTests.UnixOnly)]
+    public static void ChildProcess_ReceivesBothEnvVarsDifferingByCase_WhenCaseSensitive()
+    {
+        // Unlike on Unix, Windows (by default) does not allow for variables that differ only by case.
+        string testVarName = "CASE_VAR_" + Guid.NewGuid().ToString("N");
+        ProcessStartOptions options = new("printenv") { Arguments = { testVarName, testVarName.ToLowerInvariant() } };
+        options.EnvironmentCaseSensitivityOverride = EnvironmentCaseSensitivity.CaseSensitive;
+
+        options.Environment[testVarName] = "first";
+        options.Environment[testVarName.ToLowerInvariant()] = "second";
+
+        ProcessOutput output = ChildProcess.CaptureOutput(options);
+
+        Assert.Equal(0, output.ExitStatus.ExitCode);
+        Assert.Equal(["first", "second"], output.StandardOutput.Split('\n', StringSplitOptions.RemoveEmptyEntries));
+    }
+
     [Fact]
     public static void ChildProcess_DoesNotReceiveRemovedEnvVar()
     {
@@ -265,6 +998,38 @@ public partial class SafeChildProcessHandleTests
         return singleLine.Content;
     }
 
+    // Creates an executable in the given directory that prints its working directory.
+    private static ProcessStartOptions CreatePrintWorkingDirectoryExecutable(string directory)
+    {
+        if (OperatingSystem.IsWindows())
+        {
+            string copy = Path.Combine(directory, "tool.exe");
+            File.Copy(Path.Combine(Environment.SystemDirectory, "cmd.exe"), copy);
+            return new(copy) { Arguments = { "/c", "cd" } };
+        }
+
+        string script = Path.Combine(directory, "tool.sh");
+        File.WriteAllText(script, "#!/bin/sh\npwd -P\n");
+        File.SetUnixFileMode(script, UnixFileMode.UserRead | UnixFileMode.UserWrite | UnixFileMode.UserExecute);
+        return new(script);
+    }
+
+    private static string GetRealPath(string path)
+    {
+        string fullPath = Path.GetFullPath(path);
+        string? parent = Path.GetDirectoryName(fullPath);
+        if (parent is not null)
+        {
+            fullPath = Path.Combine(GetRealPath(parent), Path.GetFileName(fullPath));
+        }
+
+        FileSystemInfo? target = Directory.Exists(fullPath)
+            ? new DirectoryInfo(fullPath).ResolveLinkTarget(returnFinalTarget: true)
+            : new FileInfo(fullPath).ResolveLinkTarget(returnFinalTarget: true);
+
+        return target is null ? fullPath : GetRealPath(target.FullName);
+    }
+
     private static void SetEnvVarForReal(string name, string? value)
     {
         Environment.SetEnvironmentVariable(name, value);
@@ -371,6 +1136,103 @@ public partial class SafeChildProcessHandleTests
         Assert.False(wasKilled);
     }
 
+    [Fact]
+    public static void Kill_OnExitedButNotWaitedForProcess_ReturnsFalse()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd.exe") { Arguments = { "/c", "exit 3" } }
+            : new("sh") { Arguments = { "-c", "exit 3" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        WaitForExitWithoutReaping(processHandle);
+
+        Assert.False(processHandle.Kill());
+
+        // The original exit status is preserved, the process was not signaled.
+        ProcessExitStatus exitStatus = processHandle.WaitForExit();
+        Assert.Equal(3, exitStatus.ExitCode);
+        Assert.Null(exitStatus.Signal);
+        Assert.False(exitStatus.Canceled);
+    }
+
+    // On Unix the exited process remains a zombie until it's reaped, the handle must not be used to wait as that would reap it.
+    private static void WaitForExitWithoutReaping(SafeChildProcessHandle processHandle)
+    {
+        if (OperatingSystem.IsWindows())
+        {
+            using ManualResetEvent processExited = new(false) { SafeWaitHandle = new SafeWaitHandle(processHandle.DangerousGetHandle(), ownsHandle: false) };
+            Assert.True(processExited.WaitOne(TimeSpan.FromSeconds(5)));
+            return;
+        }
+
+        Stopwatch stopwatch = Stopwatch.StartNew();
+        while (!IsZombie(processHandle.ProcessId))
+        {
+            Assert.True(stopwatch.Elapsed < TimeSpan.FromSeconds(5), "The process did not exit in time.");
+            Thread.Sleep(TimeSpan.FromMilliseconds(10));
+        }
+
+        static bool IsZombie(int processId)
+        {
+            if (OperatingSystem.IsLinux())
+            {
+                // The state follows the command name, which is in parentheses and can contain spaces.
+                string stat = File.ReadAllText($"/proc/{processId}/stat");
+                return stat[stat.LastIndexOf(')') + 2] == 'Z';
+            }
+
+            // There is no procfs on macOS.
+            ProcessOutput output = ChildProcess.CaptureOutput(new ProcessStartOptions("ps") { Arguments = { "-o", "stat=", "-p", processId.ToString() } });
+            return output.StandardOutput.TrimStart().StartsWith('Z');
+        }
+    }
+
+    [Fact]
+    public static async Task WaitForExit_ManyConcurrentWaiters_AllGetTheSameExitStatus()
+    {
+        const int WaitersCount = 24;
+
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd.exe") { Arguments = { "/c", "exit 42" } }
+            : new("sh") { Arguments = { "-c", "exit 42" } };
+
+        for (int iteration = 0; iteration < 10; iteration++)
+        {
+            using CancellationTokenSource cts = new(TimeSpan.FromSeconds(5));
+            using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+            // Mix all the kinds of waiters, so they race to observe the exit (and reap the process on Unix).
+            Task<ProcessExitStatus>[] waiters = new Task<ProcessExitStatus>[WaitersCount];
+            for (int i = 0; i < waiters.Length; i++)
+            {
+                waiters[i] = (i % 4) switch
+                {
+                    0 => Task.Factory.StartNew(processHandle.WaitForExit, TaskCreationOptions.LongRunning),
+                    1 => Task.Factory.StartNew(() => processHandle.TryWaitForExit(TimeSpan.FromSeconds(5), out ProcessExitStatus? exitStatus)
+                        ? exitStatus
+                        : throw new TimeoutException(), TaskCreationOptions.LongRunning),
+                    2 => processHandle.WaitForExitAsync(),
+                    _ => processHandle.WaitForExitAsync(cts.Token),
+                };
+            }
+
+            ProcessExitStatus[] results = await Task.WhenAll(waiters);
+
+            // The exit status is cached once, whichever waiter observed the exit first, and handed to all of them.
+            foreach (ProcessExitStatus exitStatus in results)
+            {
+                Assert.Same(results[0], exitStatus);
+            }
+            Assert.Equal(42, results[0].ExitCode);
+            Assert.Null(results[0].Signal);
+            Assert.False(results[0].Canceled);
+
+            // Subsequent waits must not try to reap the process again.
+            Assert.Same(results[0], processHandle.WaitForExit());
+        }
+    }
+
 
     [Fact]
     public static void WaitForExitOrKillOnTimeout_KillsOnTimeout()
@@ -490,6 +1352,7 @@ public partial class SafeChildProcessHandleTests
         // Should wait for timeout, then kill, then wait for process to actually exit
         Assert.InRange(stopwatch.Elapsed, TimeSpan.FromMilliseconds(290), TimeSpan.FromSeconds(2));
         Assert.True(exitStatus.Canceled, "Process should be marked as canceled when killed due to timeout");
+        Assert.Equal(ProcessExitReason.Canceled, exitStatus.Reason);
         Assert.NotEqual(0, exitStatus.ExitCode);
 
 #if !WINDOWS
@@ -820,6 +1683,112 @@ public partial class SafeChildProcessHandleTests
         }
     }
 
+    [Fact]
+    public static void LaunchAuditCallback_SeesResolvedPath_AndAllowsTheLaunch()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd.exe") { Arguments = { "/c", "echo test" } }
+            : new("echo") { Arguments = { "test" } };
+
+        LaunchInfo? audited = null;
+        options.LaunchAuditCallback = launchInfo =>
+        {
+            audited = launchInfo;
+            return true;
+        };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+        Assert.Equal(0, processHandle.WaitForExit().ExitCode);
+
+        Assert.NotNull(audited);
+        Assert.True(Path.IsPathFullyQualified(audited.ResolvedPath), $"Expected an absolute path, got '{audited.ResolvedPath}'.");
+        Assert.Equal(ProcessStartOptions.ResolvePath(options.FileName).FileName, audited.ResolvedPath);
+        Assert.Equal(options.Arguments, audited.Arguments);
+        Assert.Null(audited.WorkingDirectory);
+    }
+
+    [Fact]
+    public static void LaunchAuditCallback_ReturningFalse_DeniesTheLaunch()
+    {
+        string marker = Path.Combine(Path.GetTempPath(), Guid.NewGuid().ToString("N"));
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd.exe") { Arguments = { "/c", $"echo denied > {marker}" } }
+            : new("touch") { Arguments = { marker } };
+        options.LaunchAuditCallback = launchInfo => !launchInfo.Arguments.Any(argument => argument.Contains(marker));
+
+        LaunchDeniedException exception = Assert.Throws<LaunchDeniedException>(
+            () => SafeChildProcessHandle.Start(options, input: null, output: null, error: null));
+
+        Assert.Null(exception.InnerException);
+        Assert.Equal(ProcessStartOptions.ResolvePath(options.FileName).FileName, exception.LaunchInfo.ResolvedPath);
+        Assert.False(File.Exists(marker));
+    }
+
+    [Fact]
+    public static void LaunchAuditCallback_Throwing_DeniesTheLaunch()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("cmd.exe") { Arguments = { "/c", "echo test" } }
+            : new("echo") { Arguments = { "test" } };
+        InvalidOperationException vetoException = new("Blocked by policy.");
+        options.LaunchAuditCallback = _ => throw vetoException;
+
+        LaunchDeniedException exception = Assert.Throws<LaunchDeniedException>(
+            () => ChildProcess.CaptureOutput(options));
+
+        Assert.Same(vetoException, exception.InnerException);
+    }
+
+    [Fact]
+    public static void TryKillTree_ReportsAndTerminatesAllDescendants()
+    {
+        // The child spawns a grandchild and a subshell that spawns two more processes, and reports when all of them are running.
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell")
+            {
+                Arguments = { "-InputFormat", "None", "-Command", "Start-Process powershell -ArgumentList '-InputFormat','None','-Command','Start-Sleep 30' -NoNewWindow; Start-Process powershell -ArgumentList '-InputFormat','None','-Command','Start-Sleep 30' -NoNewWindow; 'ready'; Start-Sleep 30" },
+            }
+            : new("sh")
+            {
+                Arguments = { "-c", "sleep 30 & (sleep 30 & sleep 30 & echo ready; wait) & wait" },
+            };
+        options.CreateNewProcessGroup = true;
+
+        File.CreatePipe(out SafeFileHandle readPipe, out SafeFileHandle writePipe);
+
+        using (StreamReader reader = new(new FileStream(readPipe, FileAccess.Read, bufferSize: 1, isAsync: false)))
+        using (SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: writePipe, error: null))
+        {
+            writePipe.Dispose();
+            Assert.Equal("ready", reader.ReadLine());
+
+            KillTreeResult result = processHandle.TryKillTree(TimeSpan.FromSeconds(5));
+
+            Assert.True(result.AllTerminated);
+            Assert.Empty(result.SurvivingProcessIds);
+            Assert.True(result.WasTerminated(processHandle.ProcessId));
+            Assert.InRange(result.ProcessIds.Count, OperatingSystem.IsWindows() ? 3 : 5, int.MaxValue);
+
+            Assert.True(processHandle.TryWaitForExit(TimeSpan.FromSeconds(1), out ProcessExitStatus? exitStatus));
+            Assert.NotEqual(0, exitStatus.ExitCode);
+        }
+    }
+
+    [Fact]
+    public static void TryKillTree_ThrowsWhenNotStartedWithCreateNewProcessGroup()
+    {
+        ProcessStartOptions options = OperatingSystem.IsWindows()
+            ? new("powershell") { Arguments = { "-InputFormat", "None", "-Command", "Start-Sleep 10" } }
+            : new("sleep") { Arguments = { "10" } };
+
+        using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+        // The process shares the process group of the test process, so the tree can't be told apart from it.
+        Assert.Throws<InvalidOperationException>(() => processHandle.TryKillTree(TimeSpan.FromSeconds(1)));
+
+        Assert.True(processHandle.Kill());
+    }
+
     [Fact]
     public static void KillOnParentExit_CanBeSetToTrue()
     {
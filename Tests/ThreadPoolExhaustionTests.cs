@@ -0,0 +1,65 @@
+using System;
+using System.Threading;
+using Microsoft.Win32.SafeHandles;
+using System.TBA;
+
+namespace Tests;
+
+// The test caps the thread pool of the whole test process, so it must not run in parallel with any other test.
+[CollectionDefinition(nameof(ThreadPoolExhaustionTests), DisableParallelization = true)]
+[Collection(nameof(ThreadPoolExhaustionTests))]
+public class ThreadPoolExhaustionTests
+{
+    [Fact]
+    public static void WaitForExit_CompletesWhenThreadPoolIsExhausted()
+    {
+        ThreadPool.GetMinThreads(out int minWorkerThreads, out int minCompletionPortThreads);
+        ThreadPool.GetMaxThreads(out int maxWorkerThreads, out int maxCompletionPortThreads);
+        int blockedWorkItems = 0;
+        bool probeHasRun = false;
+        // Intentionally not disposed: blocked work items may still be waiting on it when the test ends.
+        ManualResetEventSlim release = new();
+
+        // Otherwise the pool would inject new threads after a while, so it would never be exhausted for long.
+        Assert.True(ThreadPool.SetMaxThreads(minWorkerThreads, minCompletionPortThreads));
+        try
+        {
+            // Block all the threads the thread pool is allowed to have.
+            for (int i = 0; i < minWorkerThreads; i++)
+            {
+                ThreadPool.UnsafeQueueUserWorkItem(_ =>
+                {
+                    Interlocked.Increment(ref blockedWorkItems);
+                    release.Wait();
+                    Interlocked.Decrement(ref blockedWorkItems);
+                }, null);
+            }
+            Assert.True(SpinWait.SpinUntil(() => Volatile.Read(ref blockedWorkItems) == minWorkerThreads, TimeSpan.FromSeconds(5)),
+                "All the thread pool threads should be blocked");
+
+            // It can't run before the blocked work items are released, which proves that the pool is exhausted.
+            ThreadPool.UnsafeQueueUserWorkItem(_ => Volatile.Write(ref probeHasRun, true), null);
+
+            ProcessStartOptions options = OperatingSystem.IsWindows()
+                ? new("cmd.exe") { Arguments = { "/c", "exit 42" } }
+                : new("sh") { Arguments = { "-c", "exit 42" } };
+
+            using SafeChildProcessHandle processHandle = SafeChildProcessHandle.Start(options, input: null, output: null, error: null);
+
+            ProcessExitStatus? exitStatus = null;
+            Thread waiter = new(() => exitStatus = processHandle.WaitForExit()) { IsBackground = true };
+            waiter.Start();
+
+            Assert.True(waiter.Join(TimeSpan.FromSeconds(5)), "WaitForExit should not depend on the thread pool");
+            Assert.Equal(42, exitStatus!.ExitCode);
+            Assert.False(Volatile.Read(ref probeHasRun), "The thread pool should still be exhausted");
+        }
+        finally
+        {
+            release.Set();
+            ThreadPool.SetMaxThreads(maxWorkerThreads, maxCompletionPortThreads);
+            // Hand the threads back to the pool before the next test starts.
+            SpinWait.SpinUntil(() => Volatile.Read(ref blockedWorkItems) == 0 && Volatile.Read(ref probeHasRun), TimeSpan.FromSeconds(5));
+        }
+    }
+}